@@ -0,0 +1,161 @@
+package gotraceroute
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AddrClass is a class of the address space an ip address belongs to
+type AddrClass string
+
+const (
+	// AddrPublic is a globally routable address
+	AddrPublic AddrClass = "public"
+	// AddrPrivate is an RFC1918 private address (or IPv6 unique local address)
+	AddrPrivate AddrClass = "private"
+	// AddrShared is an RFC6598 shared address space (carrier grade NAT)
+	AddrShared AddrClass = "shared"
+	// AddrLoopback is a loopback address
+	AddrLoopback AddrClass = "loopback"
+	// AddrLinkLocal is a link-local address
+	AddrLinkLocal AddrClass = "link-local"
+	// AddrMulticast is a multicast address
+	AddrMulticast AddrClass = "multicast"
+	// AddrDocumentation is an address reserved for documentation (RFC5737, RFC3849)
+	AddrDocumentation AddrClass = "documentation"
+	// AddrReserved is any other reserved address that should never be seen on the internet (bogon)
+	AddrReserved AddrClass = "reserved"
+)
+
+type addrClassNet struct {
+	net   *net.IPNet
+	class AddrClass
+}
+
+// addrClassNets is a list of special purpose address blocks, see RFC6890
+var addrClassNets = func() (nets []addrClassNet) {
+	for _, c := range []struct {
+		cidr  string
+		class AddrClass
+	}{
+		{"0.0.0.0/8", AddrReserved},
+		{"10.0.0.0/8", AddrPrivate},
+		{"100.64.0.0/10", AddrShared},
+		{"127.0.0.0/8", AddrLoopback},
+		{"169.254.0.0/16", AddrLinkLocal},
+		{"172.16.0.0/12", AddrPrivate},
+		{"192.0.0.0/24", AddrReserved},
+		{"192.0.2.0/24", AddrDocumentation},
+		{"192.88.99.0/24", AddrReserved},
+		{"192.168.0.0/16", AddrPrivate},
+		{"198.18.0.0/15", AddrReserved},
+		{"198.51.100.0/24", AddrDocumentation},
+		{"203.0.113.0/24", AddrDocumentation},
+		{"224.0.0.0/4", AddrMulticast},
+		{"240.0.0.0/4", AddrReserved},
+		{"::/128", AddrReserved},
+		{"::1/128", AddrLoopback},
+		{"fc00::/7", AddrPrivate},
+		{"fe80::/10", AddrLinkLocal},
+		{"ff00::/8", AddrMulticast},
+		{"2001:db8::/32", AddrDocumentation},
+	} {
+		_, n, _ := net.ParseCIDR(c.cidr)
+		nets = append(nets, addrClassNet{net: n, class: c.class})
+	}
+	return
+}()
+
+var ipv6GlobalUnicast = &net.IPNet{IP: net.ParseIP("2000::"), Mask: net.CIDRMask(3, 128)}
+
+// ClassifyAddr returns the class of the address space the ip belongs to.
+// It returns an empty class if ip is nil
+func ClassifyAddr(ip net.IP) AddrClass {
+	if ip == nil {
+		return ""
+	}
+	for _, c := range addrClassNets {
+		if c.net.Contains(ip) {
+			return c.class
+		}
+	}
+	if ip.To4() == nil && !ipv6GlobalUnicast.Contains(ip) {
+		return AddrReserved
+	}
+	return AddrPublic
+}
+
+// IsPublic returns true if the class is a globally routable address space
+func (c AddrClass) IsPublic() bool {
+	return c == AddrPublic
+}
+
+// PathAddrSummary describes address spaces a traceroute path goes through
+type PathAddrSummary struct {
+	// FirstPublic is the step of the first hop with a public address, 0 if the path has no public hops
+	FirstPublic int
+	// Classes is the number of responded hops per address class
+	Classes map[AddrClass]int
+	// NonPublicAfter contains the hops with non-public addresses met after the path left private space,
+	// that is usually a sign of leaked private or bogon addresses
+	NonPublicAfter []Hop
+}
+
+// SummarizeAddrs returns the summary of address spaces the hops belong to
+func SummarizeAddrs(hops []Hop) (s PathAddrSummary) {
+	s.Classes = map[AddrClass]int{}
+	for _, h := range hops {
+		if !h.Success {
+			continue
+		}
+		class := h.Node.Class
+		if class == "" {
+			class = ClassifyAddr(h.Node.IP)
+		}
+		s.Classes[class]++
+		if class.IsPublic() {
+			if s.FirstPublic == 0 {
+				s.FirstPublic = h.Step
+			}
+		} else if s.FirstPublic != 0 {
+			h.Node.Class = class
+			s.NonPublicAfter = append(s.NonPublicAfter, h)
+		}
+	}
+	return
+}
+
+func (s PathAddrSummary) String() string {
+	var str string
+	switch {
+	case s.sum() == 0:
+		return "no hops responded"
+	case s.FirstPublic == 0:
+		str = "path doesn't leave non-public address space"
+	case s.sumNonPublicBefore() == 0:
+		str = "path is public from the first responded hop"
+	default:
+		str = fmt.Sprintf("path leaves private space at hop %d", s.FirstPublic)
+	}
+	if len(s.NonPublicAfter) > 0 {
+		var after []string
+		for _, h := range s.NonPublicAfter {
+			after = append(after, fmt.Sprintf("%d (%s %s)", h.Step, h.Node.IP.String(), h.Node.Class))
+		}
+		str += ", non-public addresses after that at hops " + strings.Join(after, ", ")
+	}
+	return str
+}
+
+func (s PathAddrSummary) sum() (n int) {
+	for _, c := range s.Classes {
+		n += c
+	}
+	return
+}
+
+// sumNonPublicBefore returns the number of non-public hops before the path left private space
+func (s PathAddrSummary) sumNonPublicBefore() int {
+	return s.sum() - s.Classes[AddrPublic] - len(s.NonPublicAfter)
+}
@@ -0,0 +1,53 @@
+package gotraceroute
+
+import (
+	"net"
+	"testing"
+)
+
+func TestClassifyAddr(t *testing.T) {
+	for addr, class := range map[string]AddrClass{
+		"192.168.1.1":    AddrPrivate,
+		"172.31.0.1":     AddrPrivate,
+		"100.72.1.1":     AddrShared,
+		"127.0.0.1":      AddrLoopback,
+		"169.254.10.1":   AddrLinkLocal,
+		"224.0.0.5":      AddrMulticast,
+		"198.51.100.7":   AddrDocumentation,
+		"240.0.0.1":      AddrReserved,
+		"0.1.2.3":        AddrReserved,
+		"8.8.8.8":        AddrPublic,
+		"2001:db8::1":    AddrDocumentation,
+		"2a00:1450::1":   AddrPublic,
+		"fe80::1":        AddrLinkLocal,
+		"fd00::1":        AddrPrivate,
+		"not an address": "",
+	} {
+		if c := ClassifyAddr(net.ParseIP(addr)); c != class {
+			t.Errorf("ClassifyAddr(%v) = %q, expected %q", addr, c, class)
+		}
+	}
+}
+
+func TestSummarizeAddrs(t *testing.T) {
+	var hops []Hop
+	for i, addr := range []string{"192.168.0.1", "100.64.0.1", "", "8.8.4.4", "10.0.0.1", "8.8.8.8"} {
+		h := Hop{Step: i + 1}
+		if addr != "" {
+			h.Success = true
+			h.Node.IP = net.ParseIP(addr)
+		}
+		hops = append(hops, h)
+	}
+	s := SummarizeAddrs(hops)
+	if s.FirstPublic != 4 {
+		t.Errorf("expected path to leave private space at hop 4, got %d", s.FirstPublic)
+	}
+	if len(s.NonPublicAfter) != 1 || s.NonPublicAfter[0].Step != 5 {
+		t.Errorf("expected non-public hop 5 after public space, got %v", s.NonPublicAfter)
+	}
+	expected := "path leaves private space at hop 4, non-public addresses after that at hops 5 (10.0.0.1 private)"
+	if s.String() != expected {
+		t.Errorf("unexpected summary: %q", s.String())
+	}
+}
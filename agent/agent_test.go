@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHopConversion(t *testing.T) {
+	h := gotraceroute.Hop{
+		Success:  true,
+		Node:     gotraceroute.Addr{Host: "router", IP: net.ParseIP("192.0.2.1"), Class: gotraceroute.AddrDocumentation},
+		Step:     3,
+		Sent:     time.Now(),
+		Received: time.Now(),
+		Elapsed:  12 * time.Millisecond,
+		IXP:      &gotraceroute.IXP{Name: "IX", Prefix: "192.0.2.0/24", MemberASN: 64500},
+	}
+	c := HopFromProto(HopToProto(h))
+	if !c.Node.IP.Equal(h.Node.IP) || c.Node.Class != h.Node.Class || c.Step != 3 || c.Elapsed != h.Elapsed ||
+		!c.Sent.Equal(h.Sent) || c.IXP == nil || c.IXP.MemberASN != 64500 || c.Dst.IP != nil {
+		t.Errorf("hop conversion failed: %v", c.String())
+	}
+}
+
+func TestAgent(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	(&Server{}).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	client, err := Dial(lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err = client.Run(ctx, "266.266.266.266", gotraceroute.Options{}); err == nil {
+		t.Errorf("expected error on invalid host")
+	}
+
+	c, err := client.Run(ctx, "127.0.0.1", gotraceroute.Options{MaxHops: 3})
+	if err != nil {
+		t.Fatalf("remote traceroute failed: %v", err)
+	}
+	var hops []gotraceroute.Hop
+	for hop := range c {
+		hops = append(hops, hop)
+	}
+	if len(hops) == 0 || !hops[len(hops)-1].Node.IP.Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("unexpected remote traceroute result: %v", hops)
+	}
+}
+
+func TestAgentInvalidOptions(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	(&Server{}).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	client, err := Dial(lis.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, o := range []*Options{
+		{PayloadSize: -1},
+		{Retries: -1},
+		{Retries: DefaultMaxRetries + 1},
+		{Timeout: durationpb.New(DefaultMaxTimeout + time.Second)},
+		{PayloadSize: DefaultMaxPayloadSize + 1},
+	} {
+		stream, err := client.client.Trace(ctx, &TraceRequest{Target: "127.0.0.1", Options: o})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("options %v: expected invalid argument error, got %v", o, err)
+		}
+	}
+}
+
+func TestRunError(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{fmt.Errorf("can't open the flow: %w", gotraceroute.ErrTooManyFlows), codes.ResourceExhausted},
+		{&net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}, codes.NotFound},
+		{&net.DNSError{Err: "i/o timeout", Name: "example.com", IsTimeout: true}, codes.Unavailable},
+		{os.ErrPermission, codes.Internal},
+	} {
+		if code := status.Code(runError(c.err)); code != c.code {
+			t.Errorf("%v: expected %v, got %v", c.err, c.code, code)
+		}
+	}
+}
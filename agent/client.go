@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"github.com/archer-v/gotraceroute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+// Client runs traceroutes on a remote trace agent
+type Client struct {
+	conn   *grpc.ClientConn
+	client TraceAgentClient
+}
+
+// Dial creates a client of the agent at the address target, if no options are given,
+// an insecure connection is used
+func Dial(target string, opts ...grpc.DialOption) (c *Client, err error) {
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return
+	}
+	return &Client{conn: conn, client: NewTraceAgentClient(conn)}, nil
+}
+
+// Close closes the client connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Run executes a traceroute on the agent to the dest host like gotraceroute.Run does locally.
+// Run returns when the agent has started the traceroute, hops should be read from the returned channel,
+// the channel is closed on finish or error. Cancelling ctx cancels the traceroute on the agent
+func (c *Client) Run(ctx context.Context, dest string, options gotraceroute.Options) (hops chan gotraceroute.Hop, err error) {
+	stream, err := c.client.Trace(ctx, &TraceRequest{Target: dest, Options: OptionsToProto(options)})
+	if err != nil {
+		return
+	}
+	// the agent sends headers once the traceroute is started,
+	// if the call is terminated without headers, the error status is returned by Recv
+	md, err := stream.Header()
+	if err == nil && md == nil {
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	hops = make(chan gotraceroute.Hop)
+	go func() {
+		defer close(hops)
+		for {
+			hop, e := stream.Recv()
+			if e != nil {
+				return
+			}
+			select {
+			case hops <- HopFromProto(hop):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return
+}
+
+// RunBlock executes a traceroute on the agent and returns hops when it's finished
+func (c *Client) RunBlock(ctx context.Context, dest string, options gotraceroute.Options) (hops []gotraceroute.Hop, err error) {
+	stream, err := c.client.Trace(ctx, &TraceRequest{Target: dest, Options: OptionsToProto(options)})
+	if err != nil {
+		return
+	}
+	for {
+		hop, e := stream.Recv()
+		if errors.Is(e, io.EOF) {
+			return
+		}
+		if e != nil {
+			return hops, statusError(e)
+		}
+		hops = gotraceroute.UpdateHops(hops, HopFromProto(hop))
+	}
+}
+
+// statusError converts grpc status error to a plain error with the agent message
+func statusError(err error) error {
+	if s, ok := status.FromError(err); ok {
+		return errors.New(s.Message())
+	}
+	return err
+}
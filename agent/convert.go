@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"github.com/archer-v/gotraceroute"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"time"
+)
+
+// OptionsToProto converts traceroute options to the wire format,
+// Collector, IXPDB and ASNLookup options are local to the process and aren't transferred
+func OptionsToProto(o gotraceroute.Options) *Options {
+	return &Options{
+		Port:             int32(o.Port),
+		MaxHops:          int32(o.MaxHops),
+		StartTtl:         int32(o.StartTTL),
+		Timeout:          durationpb.New(o.Timeout),
+		Retries:          int32(o.Retries),
+		PayloadSize:      int32(o.PayloadSize),
+		NetworkInterface: o.NetworkInterface,
+		DontResolve:      o.DontResolve,
+		AdaptiveTimeout:  o.AdaptiveTimeout,
+		MinTimeout:       durationpb.New(o.MinTimeout),
+		MaxTimeout:       durationpb.New(o.MaxTimeout),
+		ProbesPerHop:     int32(o.ProbesPerHop),
+	}
+}
+
+// OptionsFromProto converts the wire format options to traceroute options
+func OptionsFromProto(o *Options) gotraceroute.Options {
+	return gotraceroute.Options{
+		Port:             int(o.GetPort()),
+		MaxHops:          int(o.GetMaxHops()),
+		StartTTL:         int(o.GetStartTtl()),
+		Timeout:          o.GetTimeout().AsDuration(),
+		Retries:          int(o.GetRetries()),
+		PayloadSize:      int(o.GetPayloadSize()),
+		NetworkInterface: o.GetNetworkInterface(),
+		DontResolve:      o.GetDontResolve(),
+		AdaptiveTimeout:  o.GetAdaptiveTimeout(),
+		MinTimeout:       o.GetMinTimeout().AsDuration(),
+		MaxTimeout:       o.GetMaxTimeout().AsDuration(),
+		ProbesPerHop:     int(o.GetProbesPerHop()),
+	}
+}
+
+func addrToProto(a gotraceroute.Addr) *Addr {
+	ip := a.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &Addr{Host: a.Host, Ip: ip, Class: string(a.Class)}
+}
+
+func addrFromProto(a *Addr) gotraceroute.Addr {
+	var ip net.IP
+	if len(a.GetIp()) > 0 {
+		ip = net.IP(a.GetIp())
+	}
+	return gotraceroute.Addr{Host: a.GetHost(), IP: ip, Class: gotraceroute.AddrClass(a.GetClass())}
+}
+
+func timeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeFromProto(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime().Local()
+}
+
+// HopToProto converts a hop to the wire format, the Attempt isn't transferred
+func HopToProto(h gotraceroute.Hop) *Hop {
+	p := &Hop{
+		Success:   h.Success,
+		Src:       addrToProto(h.Src),
+		Dst:       addrToProto(h.Dst),
+		Node:      addrToProto(h.Node),
+		Step:      int32(h.Step),
+		Id:        int32(h.ID),
+		DstPort:   int32(h.DstPort),
+		Sent:      timeToProto(h.Sent),
+		Received:  timeToProto(h.Received),
+		Elapsed:   durationpb.New(h.Elapsed),
+		IcmpType:  int32(h.IcmpType),
+		IcmpCode:  int32(h.IcmpCode),
+		ReplyTtl:  int32(h.ReplyTTL),
+		ReplyIpId: int32(h.ReplyIPID),
+		ReplySize: int32(h.ReplySize),
+		Late:      h.Late,
+	}
+	if h.IXP != nil {
+		p.Ixp = &IXP{Name: h.IXP.Name, Prefix: h.IXP.Prefix, MemberAsn: int32(h.IXP.MemberASN), MemberName: h.IXP.MemberName}
+	}
+	for _, r := range h.Probes {
+		p.Probes = append(p.Probes, &HopProbe{Success: r.Success, Node: addrToProto(r.Node), Elapsed: durationpb.New(r.Elapsed),
+			IcmpType: int32(r.IcmpType), IcmpCode: int32(r.IcmpCode), ReplyTtl: int32(r.ReplyTTL), ReplySize: int32(r.ReplySize), Late: r.Late})
+	}
+	return p
+}
+
+// HopFromProto converts the wire format hop to the traceroute hop
+func HopFromProto(p *Hop) gotraceroute.Hop {
+	h := gotraceroute.Hop{
+		Success:   p.GetSuccess(),
+		Src:       addrFromProto(p.GetSrc()),
+		Dst:       addrFromProto(p.GetDst()),
+		Node:      addrFromProto(p.GetNode()),
+		Step:      int(p.GetStep()),
+		ID:        int(p.GetId()),
+		DstPort:   int(p.GetDstPort()),
+		Sent:      timeFromProto(p.GetSent()),
+		Received:  timeFromProto(p.GetReceived()),
+		Elapsed:   p.GetElapsed().AsDuration(),
+		IcmpType:  int(p.GetIcmpType()),
+		IcmpCode:  int(p.GetIcmpCode()),
+		ReplyTTL:  int(p.GetReplyTtl()),
+		ReplyIPID: int(p.GetReplyIpId()),
+		ReplySize: int(p.GetReplySize()),
+		Late:      p.GetLate(),
+	}
+	if x := p.GetIxp(); x != nil {
+		h.IXP = &gotraceroute.IXP{Name: x.GetName(), Prefix: x.GetPrefix(), MemberASN: int(x.GetMemberAsn()), MemberName: x.GetMemberName()}
+	}
+	for _, r := range p.GetProbes() {
+		h.Probes = append(h.Probes, gotraceroute.HopProbe{Success: r.GetSuccess(), Node: addrFromProto(r.GetNode()),
+			Elapsed: r.GetElapsed().AsDuration(), IcmpType: int(r.GetIcmpType()), IcmpCode: int(r.GetIcmpCode()),
+			ReplyTTL: int(r.GetReplyTtl()), ReplySize: int(r.GetReplySize()), Late: r.GetLate()})
+	}
+	return h
+}
@@ -0,0 +1,6 @@
+// Package agent provides the gRPC TraceAgent service that runs traceroutes on remote hosts:
+// Server wraps gotraceroute.Run and streams hops to the caller, Client drives remote agents
+// with the same Run/RunBlock semantics as the library. The service is defined in traceroute.proto.
+package agent
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative traceroute.proto
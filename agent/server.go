@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"time"
+)
+
+// limits of the trace options accepted from clients if the Server limits aren't set
+const (
+	DefaultMaxTimeout      = 5 * time.Second
+	DefaultMaxRetries      = 10
+	DefaultMaxPayloadSize  = 1472
+	DefaultMaxProbesPerHop = 16
+)
+
+// Server implements TraceAgentServer by running traceroutes with gotraceroute.Run on the local host
+type Server struct {
+	UnimplementedTraceAgentServer
+	// Collector, if set, receives probe events of all traceroutes run by the server
+	Collector gotraceroute.Collector
+	// MaxTimeout, MaxRetries, MaxPayloadSize and MaxProbesPerHop limit the trace options of clients,
+	// traces with greater values are rejected, the defaults are used if they aren't set
+	MaxTimeout      time.Duration
+	MaxRetries      int
+	MaxPayloadSize  int
+	MaxProbesPerHop int
+}
+
+func (s *Server) maxTimeout() time.Duration {
+	if s.MaxTimeout == 0 {
+		return DefaultMaxTimeout
+	}
+	return s.MaxTimeout
+}
+
+func (s *Server) maxRetries() int {
+	if s.MaxRetries == 0 {
+		return DefaultMaxRetries
+	}
+	return s.MaxRetries
+}
+
+func (s *Server) maxPayloadSize() int {
+	if s.MaxPayloadSize == 0 {
+		return DefaultMaxPayloadSize
+	}
+	return s.MaxPayloadSize
+}
+
+func (s *Server) maxProbesPerHop() int {
+	if s.MaxProbesPerHop == 0 {
+		return DefaultMaxProbesPerHop
+	}
+	return s.MaxProbesPerHop
+}
+
+// validate checks the trace options received from the client are in the server limits
+func (s *Server) validate(o gotraceroute.Options) error {
+	if o.Port < 0 || o.Port > 0xffff || o.MaxHops < 0 || o.StartTTL < 0 || o.Retries < 0 || o.PayloadSize < 0 ||
+		o.ProbesPerHop < 0 || o.Timeout < 0 || o.MinTimeout < 0 || o.MaxTimeout < 0 {
+		return errors.New("negative or out of range trace options")
+	}
+	switch {
+	case o.Timeout > s.maxTimeout() || o.MinTimeout > s.maxTimeout() || o.MaxTimeout > s.maxTimeout():
+		return fmt.Errorf("timeout is greater than %v", s.maxTimeout())
+	case o.Retries > s.maxRetries():
+		return fmt.Errorf("retries is greater than %v", s.maxRetries())
+	case o.PayloadSize > s.maxPayloadSize():
+		return fmt.Errorf("payload size is greater than %v", s.maxPayloadSize())
+	case o.ProbesPerHop > s.maxProbesPerHop():
+		return fmt.Errorf("probes per hop is greater than %v", s.maxProbesPerHop())
+	}
+	return nil
+}
+
+// runError returns the status error of the traceroute start failure, so clients can tell
+// retryable failures from the ones that won't go away
+func runError(err error) error {
+	var dnsErr *net.DNSError
+	code := codes.Internal
+	switch {
+	case errors.Is(err, gotraceroute.ErrTooManyFlows):
+		code = codes.ResourceExhausted
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		code = codes.NotFound
+	case errors.As(err, &dnsErr):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// Register registers the trace agent server on the grpc server
+func (s *Server) Register(srv *grpc.Server) {
+	RegisterTraceAgentServer(srv, s)
+}
+
+// Trace runs a traceroute and streams hops to the client,
+// the traceroute is cancelled when the client cancels the call or disconnects.
+// Invalid options are reported with InvalidArgument, start failures with the codes of runError
+func (s *Server) Trace(req *TraceRequest, stream TraceAgent_TraceServer) error {
+	options := OptionsFromProto(req.GetOptions())
+	if err := s.validate(options); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	options.Collector = s.Collector
+
+	c, err := gotraceroute.Run(stream.Context(), req.GetTarget(), options)
+	if err != nil {
+		return runError(err)
+	}
+	// headers tell the client the traceroute has been started
+	if err = stream.SendHeader(metadata.MD{}); err != nil {
+		for range c {
+		}
+		return err
+	}
+
+	for hop := range c {
+		if err = stream.Send(HopToProto(hop)); err != nil {
+			// the stream context is cancelled, so the traceroute is finishing, drain the channel
+			for range c {
+			}
+			return err
+		}
+	}
+	return stream.Context().Err()
+}
@@ -0,0 +1,132 @@
+// TraceAgent service allows a central controller to run traceroutes on remote agents.
+// Go code is generated with:
+//   protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative traceroute.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: traceroute.proto
+
+package agent
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TraceAgent_Trace_FullMethodName = "/gotraceroute.agent.v1.TraceAgent/Trace"
+)
+
+// TraceAgentClient is the client API for TraceAgent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TraceAgentClient interface {
+	// Trace runs a traceroute to the target and streams hops as they are received.
+	// Cancelling the call cancels the traceroute on the agent.
+	Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Hop], error)
+}
+
+type traceAgentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTraceAgentClient(cc grpc.ClientConnInterface) TraceAgentClient {
+	return &traceAgentClient{cc}
+}
+
+func (c *traceAgentClient) Trace(ctx context.Context, in *TraceRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Hop], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TraceAgent_ServiceDesc.Streams[0], TraceAgent_Trace_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TraceRequest, Hop]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TraceAgent_TraceClient = grpc.ServerStreamingClient[Hop]
+
+// TraceAgentServer is the server API for TraceAgent service.
+// All implementations must embed UnimplementedTraceAgentServer
+// for forward compatibility.
+type TraceAgentServer interface {
+	// Trace runs a traceroute to the target and streams hops as they are received.
+	// Cancelling the call cancels the traceroute on the agent.
+	Trace(*TraceRequest, grpc.ServerStreamingServer[Hop]) error
+	mustEmbedUnimplementedTraceAgentServer()
+}
+
+// UnimplementedTraceAgentServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTraceAgentServer struct{}
+
+func (UnimplementedTraceAgentServer) Trace(*TraceRequest, grpc.ServerStreamingServer[Hop]) error {
+	return status.Error(codes.Unimplemented, "method Trace not implemented")
+}
+func (UnimplementedTraceAgentServer) mustEmbedUnimplementedTraceAgentServer() {}
+func (UnimplementedTraceAgentServer) testEmbeddedByValue()                    {}
+
+// UnsafeTraceAgentServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TraceAgentServer will
+// result in compilation errors.
+type UnsafeTraceAgentServer interface {
+	mustEmbedUnimplementedTraceAgentServer()
+}
+
+func RegisterTraceAgentServer(s grpc.ServiceRegistrar, srv TraceAgentServer) {
+	// If the following call panics, it indicates UnimplementedTraceAgentServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TraceAgent_ServiceDesc, srv)
+}
+
+func _TraceAgent_Trace_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TraceRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TraceAgentServer).Trace(m, &grpc.GenericServerStream[TraceRequest, Hop]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TraceAgent_TraceServer = grpc.ServerStreamingServer[Hop]
+
+// TraceAgent_ServiceDesc is the grpc.ServiceDesc for TraceAgent service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TraceAgent_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gotraceroute.agent.v1.TraceAgent",
+	HandlerType: (*TraceAgentServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Trace",
+			Handler:       _TraceAgent_Trace_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "traceroute.proto",
+}
@@ -0,0 +1,242 @@
+package gotraceroute
+
+import (
+	"bytes"
+	"context"
+	"golang.org/x/net/ipv4"
+	"net"
+	"sort"
+	"syscall"
+	"time"
+)
+
+const DefaultAliasRounds = 5
+const DefaultAliasMaxIPIDGap = 500
+
+// aliasProbeTTL is the TTL of alias resolution probes, they should reach the probed address
+const aliasProbeTTL = 64
+
+// AliasOptions type
+type AliasOptions struct {
+	// Port is the destination UDP port, it should be closed on the probed routers
+	Port int
+	// Rounds is the number of probes sent to every candidate address
+	Rounds int
+	// Timeout is the time to wait for a reply to every probe
+	Timeout time.Duration
+	// MaxIPIDGap is the max difference between consecutive IP ID values considered to be generated by the same counter
+	MaxIPIDGap       int
+	NetworkInterface string
+	// RateLimiter limits the rate of probes, it may be shared with concurrent traceroutes
+	RateLimiter *RateLimiter
+}
+
+func (o *AliasOptions) port() int {
+	if o.Port == 0 {
+		o.Port = DefaultPort
+	}
+	return o.Port
+}
+
+func (o *AliasOptions) rounds() int {
+	if o.Rounds == 0 {
+		o.Rounds = DefaultAliasRounds
+	}
+	return o.Rounds
+}
+
+func (o *AliasOptions) timeout() time.Duration {
+	if o.Timeout == 0 {
+		o.Timeout = time.Millisecond * DefaultTimeoutMs
+	}
+	return o.Timeout
+}
+
+func (o *AliasOptions) maxIPIDGap() int {
+	if o.MaxIPIDGap == 0 {
+		o.MaxIPIDGap = DefaultAliasMaxIPIDGap
+	}
+	return o.MaxIPIDGap
+}
+
+// Router is a group of interface addresses resolved to the same router
+type Router struct {
+	Interfaces []net.IP
+}
+
+// aliasSample is a reply to an alias resolution probe
+type aliasSample struct {
+	received time.Time
+	ipid     int
+	from     net.IP
+}
+
+// ResolveAliases probes candidate addresses with UDP packets to a closed port and groups them into routers.
+// Two techniques are used:
+//   - common source address: a router replies with an ICMP port unreachable from an address other than the probed one,
+//     so both addresses belong to the router;
+//   - shared IP ID counter (Ally/MIDAR-style): candidates are probed in several interleaved rounds,
+//     and addresses whose reply IP IDs form a single monotonic sequence are considered to share the router counter.
+//
+// Every candidate is returned in exactly one Router, unresponsive candidates are returned as single interface routers.
+// Only IPv4 addresses are probed, other candidates are returned as single interface routers too
+func ResolveAliases(ctx context.Context, candidates []net.IP, options AliasOptions) (routers []Router, err error) {
+	var probed []net.IP
+	for _, c := range candidates {
+		if c.To4() != nil {
+			probed = append(probed, c.To4())
+		}
+	}
+	if len(probed) == 0 {
+		return groupAliases(candidates, nil, options.maxIPIDGap()), nil
+	}
+
+	f, err := newFlow(nil, options.port(), options.NetworkInterface)
+	if err != nil {
+		return
+	}
+	defer f.close()
+
+	samples := make(map[string][]aliasSample, len(candidates))
+	var packetIdx uint16
+	var recvBuff = make([]byte, recvBufferSize)
+
+	for r := 0; r < options.rounds(); r++ {
+		for _, c := range probed {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			default:
+			}
+
+			packetIdx = (packetIdx + 1) % (1<<6 - 1)
+			packetID := int(f.flowID<<6 + packetIdx)
+			var pkt []byte
+			if pkt, err = newUDPPacket(c, options.port(), options.port(), aliasProbeTTL, packetID, nil); err != nil {
+				return
+			}
+			if err = options.RateLimiter.Wait(ctx, c); err != nil {
+				return
+			}
+			if err = f.send(pkt, c, options.port()); err != nil {
+				return
+			}
+			var s aliasSample
+			var ok bool
+			if s, ok, err = f.recvAliasReply(recvBuff, c, packetID, options.timeout()); err != nil {
+				return
+			}
+			if ok {
+				samples[c.String()] = append(samples[c.String()], s)
+			}
+		}
+	}
+
+	return groupAliases(candidates, samples, options.maxIPIDGap()), nil
+}
+
+// recvAliasReply waits for the ICMP destination unreachable reply to the probe packetID sent to the address dst
+func (f *flow) recvAliasReply(buf []byte, dst net.IP, packetID int, timeout time.Duration) (s aliasSample, ok bool, err error) {
+	deadline := time.Now().Add(timeout)
+	for timeout > 0 {
+		if err = f.setRecvTimeout(timeout); err != nil {
+			return
+		}
+		n, e := f.recv(buf)
+		now := time.Now()
+		timeout = deadline.Sub(now)
+		if e != nil {
+			if e != syscall.EWOULDBLOCK {
+				time.Sleep(time.Millisecond * 10)
+				timeout = time.Until(deadline)
+			}
+			continue
+		}
+		hop, e := extractMessage(buf[:n], false)
+		if e != nil || hop.ID != packetID || !hop.Dst.IP.Equal(dst) || hop.IcmpType != int(ipv4.ICMPTypeDestinationUnreachable) {
+			continue
+		}
+		return aliasSample{received: now, ipid: hop.ReplyIPID, from: hop.Node.IP}, true, nil
+	}
+	return
+}
+
+// ipidMonotonic returns true if the IP ID values of the samples ordered by time are generated by a single counter:
+// every next value is greater than the previous one (modulo 2^16) by no more than maxGap
+func ipidMonotonic(samples []aliasSample, maxGap int) bool {
+	if len(samples) < 2 {
+		return false
+	}
+	for i := 1; i < len(samples); i++ {
+		d := uint16(samples[i].ipid - samples[i-1].ipid)
+		if d == 0 || int(d) > maxGap {
+			return false
+		}
+	}
+	return true
+}
+
+// groupAliases groups the candidates into routers using the common source address and the shared IP ID counter tests
+func groupAliases(candidates []net.IP, samples map[string][]aliasSample, maxGap int) (routers []Router) {
+	parent := map[string]string{}
+	addrs := map[string]net.IP{}
+	var find func(a string) string
+	find = func(a string) string {
+		if parent[a] != a {
+			parent[a] = find(parent[a])
+		}
+		return parent[a]
+	}
+	add := func(ip net.IP) string {
+		a := ip.String()
+		if _, ok := parent[a]; !ok {
+			parent[a] = a
+			addrs[a] = ip
+		}
+		return a
+	}
+	union := func(a, b string) {
+		parent[find(a)] = find(b)
+	}
+
+	var usable []string
+	for _, c := range candidates {
+		a := add(c)
+		for _, s := range samples[a] {
+			if s.from != nil && !s.from.Equal(c) {
+				union(a, add(s.from))
+			}
+		}
+		if ipidMonotonic(samples[a], maxGap) {
+			usable = append(usable, a)
+		}
+	}
+
+	for i := range usable {
+		for j := i + 1; j < len(usable); j++ {
+			if find(usable[i]) == find(usable[j]) {
+				continue
+			}
+			merged := append(append([]aliasSample{}, samples[usable[i]]...), samples[usable[j]]...)
+			sort.Slice(merged, func(a, b int) bool { return merged[a].received.Before(merged[b].received) })
+			if ipidMonotonic(merged, maxGap) {
+				union(usable[i], usable[j])
+			}
+		}
+	}
+
+	groups := map[string][]net.IP{}
+	for a, ip := range addrs {
+		root := find(a)
+		groups[root] = append(groups[root], ip)
+	}
+	for _, ips := range groups {
+		sort.Slice(ips, func(i, j int) bool { return bytes.Compare(ips[i].To16(), ips[j].To16()) < 0 })
+		routers = append(routers, Router{Interfaces: ips})
+	}
+	sort.Slice(routers, func(i, j int) bool {
+		return bytes.Compare(routers[i].Interfaces[0].To16(), routers[j].Interfaces[0].To16()) < 0
+	})
+	return
+}
@@ -0,0 +1,68 @@
+package gotraceroute
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestGroupAliases(t *testing.T) {
+	candidates := []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2"), net.ParseIP("192.0.2.3"), net.ParseIP("192.0.2.4")}
+	start := time.Now()
+	samples := map[string][]aliasSample{}
+	// 192.0.2.1 and 192.0.2.2 share the counter with a wrap around,
+	// 192.0.2.3 has its own counter, 192.0.2.4 replies from 192.0.2.5
+	ids := map[string][]int{
+		"192.0.2.1": {65530, 4, 20},
+		"192.0.2.2": {65533, 10, 25},
+		"192.0.2.3": {40000, 40003, 40006},
+		"192.0.2.4": {0, 0, 0},
+	}
+	for r := 0; r < 3; r++ {
+		for i, c := range candidates {
+			from := c
+			if c.String() == "192.0.2.4" {
+				from = net.ParseIP("192.0.2.5")
+			}
+			samples[c.String()] = append(samples[c.String()], aliasSample{
+				received: start.Add(time.Duration(r*len(candidates)+i) * time.Millisecond),
+				ipid:     ids[c.String()][r],
+				from:     from,
+			})
+		}
+	}
+
+	routers := groupAliases(candidates, samples, DefaultAliasMaxIPIDGap)
+	var groups []string
+	for _, r := range routers {
+		g := ""
+		for _, ip := range r.Interfaces {
+			g += ip.String() + " "
+		}
+		groups = append(groups, g)
+	}
+	expected := []string{"192.0.2.1 192.0.2.2 ", "192.0.2.3 ", "192.0.2.4 192.0.2.5 "}
+	if len(groups) != len(expected) {
+		t.Fatalf("unexpected routers: %q", groups)
+	}
+	for i := range expected {
+		if groups[i] != expected[i] {
+			t.Errorf("unexpected routers: %q", groups)
+		}
+	}
+}
+
+func TestResolveAliasesIPv6(t *testing.T) {
+	// IPv6 candidates aren't probed and are returned as single interface routers
+	routers, err := ResolveAliases(context.Background(), []net.IP{net.ParseIP("2001:db8::2"), net.ParseIP("2001:db8::1")}, AliasOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routers) != 2 || len(routers[0].Interfaces) != 1 || routers[0].Interfaces[0].String() != "2001:db8::1" {
+		t.Errorf("unexpected routers %v", routers)
+	}
+	if _, err = newUDPPacket(net.ParseIP("2001:db8::1"), DefaultPort, DefaultPort, 1, 1, nil); err == nil {
+		t.Errorf("expected the error building an IPv4 probe to the IPv6 address")
+	}
+}
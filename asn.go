@@ -0,0 +1,137 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ASNLookup returns the AS number originating the prefix the ip address belongs to, 0 if it's unknown.
+// ASNDB is the implementation loaded from a prefix list, other sources (e.g. a whois or a BGP feed client)
+// may implement the interface and be set in Options the same way
+type ASNLookup interface {
+	LookupASN(ip net.IP) int
+}
+
+// ASNDB is a prefix to origin AS number table, addresses are matched by the longest prefix.
+// It should be created with LoadASNDB or ParseASNDB
+type ASNDB struct {
+	// asns are origin AS numbers by prefixes in the CIDR notation
+	asns map[string]int
+	// lengths are prefix lengths of the table sorted from the longest by the address length, 32 or 128
+	lengths map[int][]int
+}
+
+// LoadASNDB loads the prefix to AS number table from the file, see ParseASNDB for supported formats
+func LoadASNDB(path string) (db *ASNDB, err error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	db, err = ParseASNDB(f)
+	if err != nil {
+		err = fmt.Errorf("can't load asn database %v: %w", path, err)
+	}
+	return
+}
+
+// ParseASNDB reads the prefix to AS number table. Every line contains a prefix and an AS number separated
+// by whitespaces, the prefix is in the CIDR notation or is an address and a prefix length like in CAIDA
+// Routeviews pfx2as files:
+//
+//	192.0.2.0/24 64500
+//	198.51.100.0	24	AS64501
+//	203.0.113.0	24	64502_64503
+//
+// The first AS of a multi-origin (_ separated) or an AS set (, separated) is used,
+// empty lines and lines started with # are ignored
+func ParseASNDB(r io.Reader) (db *ASNDB, err error) {
+	db = &ASNDB{asns: map[string]int{}, lengths: map[int][]int{}}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		fields := strings.Fields(s)
+		prefix, asn := "", ""
+		switch len(fields) {
+		case 2:
+			prefix, asn = fields[0], fields[1]
+		case 3:
+			prefix, asn = fields[0]+"/"+fields[1], fields[2]
+		default:
+			return nil, fmt.Errorf("line %d: expected prefix and AS number", line)
+		}
+		if err = db.add(prefix, asn); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, l := range db.lengths {
+		sort.Sort(sort.Reverse(sort.IntSlice(l)))
+	}
+	return
+}
+
+func (db *ASNDB) add(prefix string, asn string) error {
+	_, n, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return err
+	}
+	origins := strings.FieldsFunc(strings.TrimPrefix(strings.ToUpper(asn), "AS"), func(r rune) bool { return r == '_' || r == ',' })
+	if len(origins) == 0 {
+		return fmt.Errorf("invalid AS number %v", asn)
+	}
+	a, err := strconv.Atoi(origins[0])
+	if err != nil {
+		return fmt.Errorf("invalid AS number %v", asn)
+	}
+
+	ones, bits := n.Mask.Size()
+	if _, ok := db.asns[n.String()]; !ok {
+		found := false
+		for _, l := range db.lengths[bits] {
+			found = found || l == ones
+		}
+		if !found {
+			db.lengths[bits] = append(db.lengths[bits], ones)
+		}
+	}
+	db.asns[n.String()] = a
+	return nil
+}
+
+// Len returns the number of loaded prefixes
+func (db *ASNDB) Len() int {
+	return len(db.asns)
+}
+
+// LookupASN returns the AS number of the longest prefix the ip address belongs to, 0 if there are no such prefixes
+func (db *ASNDB) LookupASN(ip net.IP) int {
+	if db == nil || ip == nil {
+		return 0
+	}
+	bits := net.IPv6len * 8
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, net.IPv4len*8
+	}
+	for _, l := range db.lengths[bits] {
+		mask := net.CIDRMask(l, bits)
+		if asn, ok := db.asns[(&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()]; ok {
+			return asn
+		}
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package gotraceroute
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestASNDB(t *testing.T) {
+	db, err := ParseASNDB(strings.NewReader(`# prefix asn
+192.0.2.0/24 64500
+192.0.2.128	25	AS64501
+198.51.100.0	24	64502_64503
+2001:db8::/32 64504
+
+203.0.113.0/24 64505,64506
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 5 {
+		t.Errorf("expected 5 prefixes, got %v", db.Len())
+	}
+	for addr, asn := range map[string]int{
+		"192.0.2.1":        64500,
+		"192.0.2.200":      64501,
+		"198.51.100.7":     64502,
+		"2001:db8::1":      64504,
+		"203.0.113.9":      64505,
+		"::ffff:192.0.2.1": 64500,
+		"10.0.0.1":         0,
+		"2001:db9::1":      0,
+	} {
+		if a := db.LookupASN(net.ParseIP(addr)); a != asn {
+			t.Errorf("%v: expected AS%v, got AS%v", addr, asn, a)
+		}
+	}
+
+	for _, s := range []string{"192.0.2.0/24", "192.0.2.0/33 64500", "192.0.2.0/24 ASx", "192.0.2.0/24 _"} {
+		if _, err := ParseASNDB(strings.NewReader(s)); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
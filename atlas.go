@@ -0,0 +1,234 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"encoding/json"
+	"golang.org/x/net/ipv4"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+// atlasFirmware is the RIPE Atlas probe firmware version whose result format is written,
+// parsers like ripe.atlas.sagan choose the format by the fw field
+const atlasFirmware = 5080
+
+// AtlasResult is a traceroute result in the RIPE Atlas result schema,
+// see https://atlas.ripe.net/docs/apis/result-format/
+type AtlasResult struct {
+	Af        int        `json:"af"`
+	DstAddr   string     `json:"dst_addr"`
+	DstName   string     `json:"dst_name,omitempty"`
+	SrcAddr   string     `json:"src_addr,omitempty"`
+	From      string     `json:"from,omitempty"`
+	Fw        int        `json:"fw"`
+	MsmID     int        `json:"msm_id,omitempty"`
+	MsmName   string     `json:"msm_name"`
+	ParisID   int        `json:"paris_id"`
+	PrbID     int        `json:"prb_id"`
+	Proto     string     `json:"proto"`
+	Size      int        `json:"size"`
+	Timestamp int64      `json:"timestamp"`
+	EndTime   int64      `json:"endtime"`
+	Type      string     `json:"type"`
+	Result    []AtlasHop `json:"result"`
+}
+
+// AtlasHop is a hop of the Atlas traceroute result
+type AtlasHop struct {
+	Hop    int          `json:"hop"`
+	Error  string       `json:"error,omitempty"`
+	Result []AtlasReply `json:"result,omitempty"`
+}
+
+// AtlasReply is a result of a probe of the hop, a lost probe is {"x": "*"}
+type AtlasReply struct {
+	X    string  `json:"x,omitempty"`
+	From string  `json:"from,omitempty"`
+	RTT  float64 `json:"rtt,omitempty"`
+	Size int     `json:"size,omitempty"`
+	TTL  int     `json:"ttl,omitempty"`
+	// Err is the ICMP unreachable code: a letter (N, H, A, P, p) or a number of other codes
+	Err interface{} `json:"err,omitempty"`
+	// Late is the number of probes the reply is late by, such replies have no RTT
+	Late int  `json:"late,omitempty"`
+	Dup  bool `json:"dup,omitempty"`
+}
+
+// atlasErrs are the Atlas letters of ICMP destination unreachable codes
+var atlasErrs = map[int]string{0: "N", 1: "H", 2: "P", 3: "p", 13: "A"}
+
+// NewAtlasResult returns the Atlas result of the trace hops to target executed with options,
+// prbID is the Atlas probe id the result is attributed to
+func NewAtlasResult(hops []Hop, target string, prbID int, options Options) AtlasResult {
+	a := AtlasResult{Af: 4, DstName: target, Fw: atlasFirmware, MsmName: "Traceroute", PrbID: prbID,
+		Proto: "UDP", Size: options.payloadSize(), Type: "traceroute", Result: []AtlasHop{}}
+	if len(hops) == 0 {
+		return a
+	}
+	first, last := hops[0], hops[len(hops)-1]
+	a.DstAddr = first.Dst.IP.String()
+	if first.Src.IP != nil {
+		a.SrcAddr = first.Src.IP.String()
+	}
+	a.Timestamp = first.Sent.Unix()
+	a.EndTime = last.Sent.Add(last.Elapsed).Unix()
+	for _, h := range hops {
+		ah := AtlasHop{Hop: h.Step}
+		probes := h.Probes
+		if len(probes) == 0 {
+			probes = []HopProbe{h.probe()}
+		}
+		for _, p := range probes {
+			if !p.Success {
+				ah.Result = append(ah.Result, AtlasReply{X: "*"})
+				continue
+			}
+			r := AtlasReply{From: p.Node.IP.String(), RTT: math.Round(float64(p.Elapsed.Microseconds())) / 1000,
+				Size: p.ReplySize, TTL: p.ReplyTTL}
+			// the port unreachable from the destination is the normal end of the UDP trace, not an error
+			if p.IcmpType == int(ipv4.ICMPTypeDestinationUnreachable) && !(p.IcmpCode == 3 && p.Node.IP.Equal(h.Dst.IP)) {
+				if e, ok := atlasErrs[p.IcmpCode]; ok {
+					r.Err = e
+				} else {
+					r.Err = p.IcmpCode
+				}
+			}
+			ah.Result = append(ah.Result, r)
+		}
+		a.Result = append(a.Result, ah)
+	}
+	return a
+}
+
+// Hops returns the hops of the Atlas result. Every reply of a hop becomes a HopProbe if the hop was probed
+// more than once, the hop fields are filled in from the first answered probe like Run does.
+// Duplicated and late replies are skipped, they have no RTT
+func (a AtlasResult) Hops() []Hop {
+	src, dst := net.ParseIP(a.SrcAddr), net.ParseIP(a.DstAddr)
+	sent := time.Unix(a.Timestamp, 0)
+	hops := make([]Hop, 0, len(a.Result))
+	for _, ah := range a.Result {
+		h := Hop{Src: Addr{IP: src}, Dst: Addr{Host: a.DstName, IP: dst}, Step: ah.Hop, Sent: sent}
+		var probes []HopProbe
+		for _, r := range ah.Result {
+			if r.Dup || r.Late > 0 {
+				continue
+			}
+			p := HopProbe{}
+			if ip := net.ParseIP(r.From); r.X == "" && ip != nil {
+				p = HopProbe{Success: true, Node: Addr{IP: ip, Class: ClassifyAddr(ip)},
+					Elapsed: time.Duration(r.RTT * float64(time.Millisecond)), ReplyTTL: r.TTL, ReplySize: r.Size}
+				p.IcmpType, p.IcmpCode = a.icmpTypeCode(r, ip)
+			}
+			probes = append(probes, p)
+		}
+		for _, p := range probes {
+			if p.Success {
+				h.Success, h.Node, h.Elapsed, h.Received = true, p.Node, p.Elapsed, sent.Add(p.Elapsed)
+				h.IcmpType, h.IcmpCode, h.ReplyTTL, h.ReplySize = p.IcmpType, p.IcmpCode, p.ReplyTTL, p.ReplySize
+				break
+			}
+		}
+		if len(probes) > 1 {
+			h.Probes = probes
+		}
+		hops = append(hops, h)
+	}
+	return hops
+}
+
+// icmpTypeCode returns the ICMP type and code of the reply from the node ip, Atlas writes only unreachable codes
+func (a AtlasResult) icmpTypeCode(r AtlasReply, ip net.IP) (icmpType, code int) {
+	switch e := r.Err.(type) {
+	case string:
+		for c, l := range atlasErrs {
+			if l == e {
+				return int(ipv4.ICMPTypeDestinationUnreachable), c
+			}
+		}
+		if c, err := strconv.Atoi(e); err == nil {
+			return int(ipv4.ICMPTypeDestinationUnreachable), c
+		}
+	case float64:
+		return int(ipv4.ICMPTypeDestinationUnreachable), int(e)
+	}
+	if ip.Equal(net.ParseIP(a.DstAddr)) {
+		switch a.Proto {
+		case "UDP":
+			return int(ipv4.ICMPTypeDestinationUnreachable), 3
+		case "ICMP":
+			return int(ipv4.ICMPTypeEchoReply), 0
+		}
+		return 0, 0
+	}
+	return int(ipv4.ICMPTypeTimeExceeded), 0
+}
+
+// DecodeAtlas reads Atlas traceroute results: a JSON array as the Atlas API returns it
+// or a stream of JSON result objects, e.g. the streaming API output or AtlasEncoder output
+func DecodeAtlas(r io.Reader) (results []AtlasResult, err error) {
+	br := bufio.NewReader(r)
+	first, err := peekJSON(br)
+	if err != nil || first == 0 {
+		return
+	}
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		err = dec.Decode(&results)
+		return
+	}
+	for {
+		var a AtlasResult
+		if err = dec.Decode(&a); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		results = append(results, a)
+	}
+}
+
+// AtlasEncoder writes the trace as a RIPE Atlas traceroute result, the result is written by Close
+// on a single line, so results of several traces written to the same writer make a stream DecodeAtlas reads.
+// Late updates replace hops of the same step
+type AtlasEncoder struct {
+	w       io.Writer
+	target  string
+	prbID   int
+	options Options
+	hops    []Hop
+}
+
+// NewAtlasEncoder returns the encoder of the trace to target executed with options,
+// prbID is the Atlas probe id the result is attributed to
+func NewAtlasEncoder(w io.Writer, target string, prbID int, options Options) *AtlasEncoder {
+	return &AtlasEncoder{w: w, target: target, prbID: prbID, options: options}
+}
+
+// Encode adds the hop to the result
+func (e *AtlasEncoder) Encode(h Hop) error {
+	e.hops = UpdateHops(e.hops, h)
+	return nil
+}
+
+// Close writes the result, err is written as the error of the hop following the last one
+func (e *AtlasEncoder) Close(err error) error {
+	a := NewAtlasResult(e.hops, e.target, e.prbID, e.options)
+	if err != nil {
+		step := e.options.startTTL()
+		if len(e.hops) > 0 {
+			step = e.hops[len(e.hops)-1].Step + 1
+		}
+		a.Result = append(a.Result, AtlasHop{Hop: step, Error: err.Error()})
+	}
+	d, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(d, '\n'))
+	return err
+}
@@ -0,0 +1,94 @@
+package gotraceroute
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// atlasSample is a result of an Atlas UDP traceroute measurement as the Atlas API returns it
+const atlasSample = `[{"af":4,"dst_addr":"193.0.14.129","dst_name":"k.root-servers.net","endtime":1700000010,
+"from":"198.51.100.7","fw":5080,"lts":12,"msm_id":5001,"msm_name":"Traceroute","paris_id":3,"prb_id":1000,
+"proto":"UDP","result":[
+ {"hop":1,"result":[{"from":"192.168.1.1","rtt":1.234,"size":28,"ttl":64},{"from":"192.168.1.1","rtt":0.95,"size":28,"ttl":64},{"from":"192.168.1.1","rtt":1.01,"size":28,"ttl":64}]},
+ {"hop":2,"result":[{"x":"*"},{"x":"*"},{"x":"*"}]},
+ {"hop":3,"result":[{"x":"*"},{"from":"203.0.113.9","rtt":12.5,"size":76,"ttl":253},{"from":"203.0.113.10","rtt":13.1,"size":76,"ttl":253},{"from":"203.0.113.10","late":1,"size":76,"ttl":253}]},
+ {"hop":4,"result":[{"from":"193.0.14.129","rtt":20.02,"size":48,"ttl":60},{"from":"193.0.14.129","rtt":20.1,"size":48,"ttl":60,"dup":true},{"from":"193.0.14.129","rtt":19.8,"size":48,"ttl":60},{"from":"193.0.14.129","err":"H","rtt":19.9,"size":48,"ttl":60}]}
+],"size":48,"src_addr":"192.168.1.100","timestamp":1700000000,"type":"traceroute"}]`
+
+func TestDecodeAtlas(t *testing.T) {
+	traces, err := DecodeTraces(strings.NewReader(atlasSample))
+	if err != nil || len(traces) != 1 {
+		t.Fatalf("DecodeTraces failed: %v", err)
+	}
+	hops := traces[0]
+	if len(hops) != 4 {
+		t.Fatalf("expected 4 hops, got %v", len(hops))
+	}
+	if h := hops[0]; !h.Success || h.Node.IP.String() != "192.168.1.1" || h.Elapsed != 1234*time.Microsecond ||
+		h.ReplyTTL != 64 || h.ReplySize != 28 || h.Node.Class != AddrPrivate || len(h.Probes) != 3 ||
+		h.Dst.Host != "k.root-servers.net" || h.Src.IP.String() != "192.168.1.100" || h.Sent.Unix() != 1700000000 {
+		t.Errorf("unexpected hop 1: %+v", h)
+	}
+	if h := hops[1]; h.Success || len(h.Probes) != 3 {
+		t.Errorf("unexpected hop 2: %+v", h)
+	}
+	// the late reply is skipped, the hop is filled in from the first answered probe
+	if h := hops[2]; !h.Success || h.Node.IP.String() != "203.0.113.9" || len(h.Probes) != 3 || h.Probes[0].Success ||
+		h.IcmpType != 11 || h.Probes[2].Node.IP.String() != "203.0.113.10" {
+		t.Errorf("unexpected hop 3: %+v", h)
+	}
+	// the duplicate is skipped, the destination replies with port unreachable, err letters are unreachable codes
+	if h := hops[3]; len(h.Probes) != 3 || h.IcmpType != 3 || h.IcmpCode != 3 || h.Probes[2].IcmpCode != 1 {
+		t.Errorf("unexpected hop 4: %+v", h)
+	}
+	topo := NewTopology()
+	topo.Add(hops)
+	if n := len(topo.Nodes()); n < 4 {
+		t.Errorf("expected at least 4 topology nodes, got %v", n)
+	}
+}
+
+func TestAtlasEncoder(t *testing.T) {
+	traces, err := DecodeTraces(strings.NewReader(atlasSample))
+	if err != nil {
+		t.Fatalf("DecodeTraces failed: %v", err)
+	}
+	var b strings.Builder
+	e := NewAtlasEncoder(&b, "k.root-servers.net", 1000, Options{PayloadSize: 48})
+	for _, h := range traces[0] {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(nil)
+	s := b.String()
+	for _, expected := range []string{`"prb_id":1000`, `"type":"traceroute"`, `"dst_addr":"193.0.14.129"`, `"size":48,`,
+		`{"hop":2,"result":[{"x":"*"},{"x":"*"},{"x":"*"}]}`, `{"from":"192.168.1.1","rtt":1.234,"size":28,"ttl":64}`,
+		`{"from":"193.0.14.129","rtt":19.9,"size":48,"ttl":60,"err":"H"}`} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("%v not found in %v", expected, s)
+		}
+	}
+
+	again, err := DecodeTraces(strings.NewReader(s))
+	if err != nil || len(again) != 1 {
+		t.Fatalf("DecodeTraces of the encoded result failed: %v", err)
+	}
+	if d := Diff(traces[0], again[0]); d.Changed() {
+		t.Errorf("round trip has changed the path:\n%v", d.String())
+	}
+	for i, h := range again[0] {
+		if h.Elapsed != traces[0][i].Elapsed || len(h.Probes) != len(traces[0][i].Probes) {
+			t.Errorf("round trip has changed hop %v: %+v", h.Step, h)
+		}
+	}
+
+	b.Reset()
+	e = NewAtlasEncoder(&b, "example.com", 0, Options{})
+	_ = e.Encode(Hop{Step: 1, Success: true, Node: Addr{IP: net.ParseIP("10.0.0.1")}, Dst: Addr{IP: net.ParseIP("192.0.2.1")}})
+	_ = e.Close(errors.New("sendto error"))
+	if !strings.Contains(b.String(), `{"hop":2,"error":"sendto error"}`) {
+		t.Errorf("the error isn't written: %v", b.String())
+	}
+}
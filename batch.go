@@ -0,0 +1,105 @@
+package gotraceroute
+
+import (
+	"context"
+	"sync"
+)
+
+const DefaultBatchConcurrency = 64
+
+// BatchOptions type
+type BatchOptions struct {
+	// Options are used for every traceroute of the batch
+	Options Options
+	// Concurrency is the max number of concurrent traceroutes, it's limited by the number of flows
+	// the BPF filter can tell apart, flow ids are shared with other traceroutes of the process,
+	// a traceroute started when all ids are in use fails
+	Concurrency int
+	// ProbesPerSecond is the probe rate budget shared by all traceroutes of the batch, 0 means unlimited,
+	// it's used if Options.RateLimiter isn't set, otherwise the traceroutes share the given limiter
+	ProbesPerSecond float64
+	// Ordered makes results to be emitted in the input order instead of the completion order
+	Ordered bool
+}
+
+func (o *BatchOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultBatchConcurrency
+	}
+	if o.Concurrency > maxFlows {
+		o.Concurrency = maxFlows
+	}
+	return o.Concurrency
+}
+
+// BatchResult is a result of one traceroute of the batch
+type BatchResult struct {
+	// Index is the target index in the input list
+	Index  int
+	Target string
+	Hops   []Hop
+	Err    error
+}
+
+// RunMany executes traceroutes to the targets under the concurrency cap and the probe rate budget.
+// RunMany is unblocked and returns a channel where the caller should read results,
+// the channel is closed when all traceroutes are finished. If ctx is done, not started traceroutes
+// are reported with the context error.
+func RunMany(ctx context.Context, targets []string, options BatchOptions) <-chan BatchResult {
+	traceOptions := options.Options
+	if options.ProbesPerSecond > 0 && traceOptions.RateLimiter == nil {
+		traceOptions.RateLimiter = NewRateLimiter(options.ProbesPerSecond, 1)
+	}
+
+	results := make(chan BatchResult)
+	done := make(chan BatchResult)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(options.concurrency(), len(targets)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				r := BatchResult{Index: idx, Target: targets[idx]}
+				if r.Err = ctx.Err(); r.Err == nil {
+					r.Hops, r.Err = runBlock(ctx, targets[idx], traceOptions)
+				}
+				done <- r
+			}
+		}()
+	}
+	go func() {
+		for i := range targets {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	go func() {
+		defer close(results)
+		if !options.Ordered {
+			for r := range done {
+				results <- r
+			}
+			return
+		}
+		pending := map[int]BatchResult{}
+		next := 0
+		for r := range done {
+			pending[r.Index] = r
+			for ; ; next++ {
+				p, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				results <- p
+			}
+		}
+	}()
+
+	return results
+}
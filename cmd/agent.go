@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute/agent"
+	"google.golang.org/grpc"
+	"log"
+	"net"
+)
+
+// agentCommand runs the gRPC trace agent driven by a remote controller
+func agentCommand(args []string) int {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	listen := fs.String("listen", ":9000", "Address to listen on")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute agent [options]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	srv := grpc.NewServer()
+	(&agent.Server{}).Register(srv)
+	log.Printf("trace agent is listening on %v", lis.Addr())
+	if err = srv.Serve(lis); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
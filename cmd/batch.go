@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// batchResult is the JSON output format of a batch traceroute result
+type batchResult struct {
+	Target string
+	Error  string `json:",omitempty"`
+	Hops   []gotraceroute.Hop
+}
+
+// batchJSONEncoder writes batch results as a JSON array, the array is opened with the first result
+// and closed by Close, so the output is a valid JSON document even if the batch is interrupted
+type batchJSONEncoder struct {
+	w      io.Writer
+	indent bool
+	count  int
+}
+
+// Encode writes the result as the next element of the array
+func (e *batchJSONEncoder) Encode(r gotraceroute.BatchResult) (err error) {
+	res := batchResult{Target: r.Target, Hops: r.Hops}
+	if r.Err != nil {
+		res.Error = r.Err.Error()
+	}
+	var d []byte
+	if e.indent {
+		d, err = json.MarshalIndent(res, "", "    ")
+	} else {
+		d, err = json.Marshal(res)
+	}
+	if err != nil {
+		return
+	}
+	sep := ","
+	if e.indent {
+		sep = ",\n"
+	}
+	if e.count == 0 {
+		sep = "["
+	}
+	if _, err = io.WriteString(e.w, sep+string(d)); err == nil {
+		e.count++
+	}
+	return
+}
+
+// Close closes the array
+func (e *batchJSONEncoder) Close() (err error) {
+	if e.count == 0 {
+		_, err = io.WriteString(e.w, "[]\n")
+	} else {
+		_, err = io.WriteString(e.w, "]\n")
+	}
+	return
+}
+
+// runBatch traces targets read from the file (or stdin) and returns the exit code:
+// 0 if all destinations were reached and 2 otherwise
+func runBatch(targetsFile string) int {
+	targets, err := readTargets(targetsFile)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	switch outputFormat {
+	case "mtr", "mtr-json", "mtr-csv":
+		fmt.Printf("output format %v isn't supported with -T\n", outputFormat)
+		return 1
+	}
+
+	// results of the targets not traced yet are reported with the error on interrupt, so the output is completed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	exitCode := 0
+	first := true
+	// csv and tsv rows of all targets make a single table, hops are told apart by the destination columns
+	var table gotraceroute.HopEncoder
+	if outputFormat == "csv" || outputFormat == "tsv" {
+		table = newEncoder(os.Stdout, "")
+	}
+	// traces of all targets are written to a single warts file
+	var warts *gotraceroute.WartsWriter
+	if outputFormat == "warts" {
+		if warts, err = gotraceroute.NewWartsWriter(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	var array *batchJSONEncoder
+	if jsonOutput {
+		array = &batchJSONEncoder{w: os.Stdout, indent: jsonFormatted}
+	}
+	for r := range gotraceroute.RunMany(ctx, targets, batchOptions) {
+		if !gotraceroute.Reached(r.Hops) {
+			exitCode = 2
+		}
+		if r.Err != nil && outputFormat != "text" && outputFormat != "ndjson" && !jsonOutput {
+			fmt.Fprintf(os.Stderr, "traceroute to %v: %v\n", r.Target, r.Err)
+		}
+		switch {
+		case table != nil:
+			for _, h := range r.Hops {
+				_ = table.Encode(h)
+			}
+		case warts != nil:
+			_ = warts.WriteTrace(r.Hops, options, r.Err)
+		case array != nil:
+			_ = array.Encode(r)
+		default:
+			if !first && (outputFormat == "text" || outputFormat == "traceroute") {
+				fmt.Println()
+			}
+			e := newEncoder(os.Stdout, r.Target)
+			for _, h := range r.Hops {
+				_ = e.Encode(h)
+			}
+			_ = e.Close(r.Err)
+		}
+		first = false
+	}
+	if array != nil {
+		_ = array.Close()
+	}
+	if table != nil {
+		_ = table.Close(nil)
+	}
+	if warts != nil {
+		_ = warts.Close()
+	}
+	return exitCode
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"github.com/archer-v/gotraceroute/agent"
+	"github.com/archer-v/gotraceroute/controller"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// controllerCommand runs a measurement campaign on the agents and writes the results as JSON lines
+func controllerCommand(args []string) int {
+	fs := flag.NewFlagSet("controller", flag.ExitOnError)
+	agents := fs.String("agents", "", "Comma separated list of agents as name=host:port")
+	local := fs.Bool("local", false, "Start a local agent and add it to the fleet")
+	targetsFile := fs.String("targets", "-", "File with targets, one per line, - means stdin")
+	output := fs.String("o", "-", "File to write results to, - means stdout")
+	storeDir := fs.String("store", "", "Directory of the trace history store to keep the results in")
+	var campaign controller.Campaign
+	var options controller.Options
+	fs.StringVar(&campaign.Name, "name", time.Now().Format(time.RFC3339), "Campaign name")
+	fs.IntVar(&options.Retries, "r", controller.DefaultRetries, "Number of retries of a failed measurement")
+	fs.IntVar(&options.AgentConcurrency, "c", controller.DefaultAgentConcurrency, "Max number of concurrent measurements per agent")
+	fs.IntVar(&campaign.Options.MaxHops, "m", gotraceroute.DefaultMaxHops, "Set the max time-to-live (max number of hops) used in outgoing probe packets")
+	fs.BoolVar(&campaign.Options.DontResolve, "n", false, "Do not resolve IP addresses to domain names")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute controller [options]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	var err error
+	if campaign.Targets, err = readTargets(*targetsFile); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if *storeDir != "" {
+		if options.Store, err = gotraceroute.OpenStore(*storeDir, gotraceroute.StoreOptions{}); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	c := controller.New(options)
+	for _, a := range strings.Split(*agents, ",") {
+		if a = strings.TrimSpace(a); a == "" {
+			continue
+		}
+		name, addr, ok := strings.Cut(a, "=")
+		if !ok {
+			name, addr = a, a
+		}
+		client, err := agent.Dial(addr)
+		if err != nil {
+			fmt.Printf("agent %v: %v\n", name, err)
+			return 1
+		}
+		defer client.Close()
+		c.Register(name, client)
+	}
+	if *local {
+		client, stop, err := controller.StartLocalAgent()
+		if err != nil {
+			fmt.Printf("can't start local agent: %v\n", err)
+			return 1
+		}
+		defer stop()
+		c.Register("local", client)
+	}
+
+	results, err := c.Run(context.Background(), campaign)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err = controller.WriteResults(w, results); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			return 2
+		}
+	}
+	return 0
+}
+
+// readTargets reads targets from the file, one per line, empty lines and lines started with # are ignored.
+// fileName "-" means stdin
+func readTargets(fileName string) (targets []string, err error) {
+	r := io.Reader(os.Stdin)
+	if fileName != "-" {
+		f, err := os.Open(fileName) // #nosec G304
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		t := strings.TrimSpace(scanner.Text())
+		if t != "" && !strings.HasPrefix(t, "#") {
+			targets = append(targets, t)
+		}
+	}
+	err = scanner.Err()
+	return
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"os"
+)
+
+// diffCommand compares two traces saved in JSON format or RIPE Atlas results and returns the exit code:
+// 0 if the path is the same, 1 if the path has changed and 2 on error
+func diffCommand(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	all := fs.Bool("a", false, "Output all hops, not only differences")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute diff [options] old.json new.json")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	var traces [2][]gotraceroute.Hop
+	for i := range traces {
+		var err error
+		if traces[i], err = readHops(fs.Arg(i)); err != nil {
+			fmt.Println(err)
+			return 2
+		}
+	}
+
+	d := gotraceroute.Diff(traces[0], traces[1])
+	for _, h := range d.Hops {
+		if *all || h.Kind != gotraceroute.HopUnchanged {
+			fmt.Println(h.String())
+		}
+	}
+	if d.Changed() {
+		fmt.Println("path has changed")
+		return 1
+	}
+	fmt.Println("path is the same")
+	return 0
+}
+
+// readHops reads the single trace of the file
+func readHops(fileName string) (hops []gotraceroute.Hop, err error) {
+	traces, err := readTraces(fileName)
+	if err != nil {
+		return
+	}
+	if len(traces) != 1 {
+		err = fmt.Errorf("traceroute result %v has %v traces, a single trace is expected", fileName, len(traces))
+		return
+	}
+	return traces[0], nil
+}
+
+// readTraces reads traces saved by this tool or RIPE Atlas results
+func readTraces(fileName string) (traces [][]gotraceroute.Hop, err error) {
+	f, err := os.Open(fileName) // #nosec G304
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	if traces, err = gotraceroute.DecodeTraces(f); err != nil {
+		err = fmt.Errorf("can't read traceroute result %v: %w", fileName, err)
+	}
+	return
+}
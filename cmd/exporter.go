@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exporterConfig is the exporter configuration file format
+type exporterConfig struct {
+	// Interval is the period of scheduled traces, e.g. "60s"
+	Interval string `json:"interval"`
+	// Window is the number of the latest traces the per-hop loss is calculated over
+	Window  int                    `json:"window"`
+	Targets []exporterTargetConfig `json:"targets"`
+}
+
+type exporterTargetConfig struct {
+	Name        string `json:"name"`
+	Host        string `json:"host"`
+	MaxHops     int    `json:"max_hops"`
+	TimeoutMs   int    `json:"timeout_ms"`
+	Retries     int    `json:"retries"`
+	PayloadSize int    `json:"payload_size"`
+}
+
+func (c exporterTargetConfig) options(base gotraceroute.Options) gotraceroute.Options {
+	base.MaxHops = c.MaxHops
+	base.Timeout = time.Duration(c.TimeoutMs) * time.Millisecond
+	base.Retries = c.Retries
+	base.PayloadSize = c.PayloadSize
+	return base
+}
+
+// targetState is the latest traces of a scheduled target
+type targetState struct {
+	config      exporterTargetConfig
+	mu          sync.Mutex
+	history     [][]gotraceroute.Hop
+	duration    time.Duration
+	pathChanges int
+	failures    int
+}
+
+type exporter struct {
+	options  gotraceroute.Options
+	counters gotraceroute.ProbeCounters
+	window   int
+	targets  []*targetState
+}
+
+func exporterCommand(args []string) int {
+	fs := flag.NewFlagSet("exporter", flag.ExitOnError)
+	listen := fs.String("listen", ":9116", "Address to listen on")
+	configFile := fs.String("config", "", "Config file with targets traced on schedule, see README for the format")
+	e := &exporter{}
+	e.options.DontResolve = true
+	e.options.Collector = &e.counters
+	fs.StringVar(&e.options.NetworkInterface, "i", "", "Set the network interface to use")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute exporter [options]")
+		fs.PrintDefaults()
+		fmt.Println(`
+Endpoints:
+  GET /metrics                 metrics of the targets traced on schedule and probe counters
+  GET /probe?target=host       trace the target on scrape and return its metrics`)
+	}
+	_ = fs.Parse(args)
+
+	interval := time.Minute
+	if *configFile != "" {
+		config, err := loadExporterConfig(*configFile)
+		if err == nil && config.Interval != "" {
+			interval, err = time.ParseDuration(config.Interval)
+		}
+		if err != nil {
+			fmt.Printf("can't load config: %v\n", err)
+			return 1
+		}
+		e.window = config.Window
+		for _, t := range config.Targets {
+			if t.Name == "" {
+				t.Name = t.Host
+			}
+			e.targets = append(e.targets, &targetState{config: t})
+		}
+	}
+	if e.window <= 0 {
+		e.window = 10
+	}
+
+	for _, t := range e.targets {
+		go e.schedule(t, interval)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.handleMetrics)
+	mux.HandleFunc("/probe", e.handleProbe)
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("exporter is listening on %v, %d scheduled targets", *listen, len(e.targets))
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+func loadExporterConfig(fileName string) (config exporterConfig, err error) {
+	data, err := os.ReadFile(fileName) // #nosec G304
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &config)
+	return
+}
+
+// schedule traces the target every interval
+func (e *exporter) schedule(t *targetState, interval time.Duration) {
+	for {
+		started := time.Now()
+		hops, err := gotraceroute.RunBlock(t.config.Host, t.config.options(e.options))
+
+		t.mu.Lock()
+		if err != nil {
+			t.failures++
+			log.Printf("trace to %v failed: %v", t.config.Host, err)
+		} else {
+			if n := len(t.history); n > 0 && gotraceroute.Diff(t.history[n-1], hops).Changed() {
+				t.pathChanges++
+			}
+			t.history = append(t.history, hops)
+			if len(t.history) > e.window {
+				t.history = t.history[1:]
+			}
+			t.duration = time.Since(started)
+		}
+		t.mu.Unlock()
+
+		time.Sleep(time.Until(started.Add(interval)))
+	}
+}
+
+func (e *exporter) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	m := newMetricsWriter()
+	for _, t := range e.targets {
+		t.mu.Lock()
+		m.target(t.config.Name, t.history, t.duration)
+		m.add("gotraceroute_path_changes_total", "counter", "Number of times the path to the target has changed.",
+			float64(t.pathChanges), "target", t.config.Name)
+		m.add("gotraceroute_trace_failures_total", "counter", "Number of traces failed to start.",
+			float64(t.failures), "target", t.config.Name)
+		t.mu.Unlock()
+	}
+	m.counters(&e.counters)
+	m.write(w)
+}
+
+func (e *exporter) handleProbe(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	options := e.options
+	if timeout := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); timeout != "" {
+		// don't wait for a trace longer than prometheus waits for the scrape
+		if d, err := time.ParseDuration(timeout + "s"); err == nil {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+	}
+
+	started := time.Now()
+	c, err := gotraceroute.Run(r.Context(), target, options)
+	m := newMetricsWriter()
+	if err != nil {
+		m.add("gotraceroute_probe_success", "gauge", "Whether the trace was started successfully.", 0)
+		m.write(w)
+		return
+	}
+	var hops []gotraceroute.Hop
+	for hop := range c {
+		hops = gotraceroute.UpdateHops(hops, hop)
+	}
+	m.add("gotraceroute_probe_success", "gauge", "Whether the trace was started successfully.", 1)
+	m.target(target, [][]gotraceroute.Hop{hops}, time.Since(started))
+	m.write(w)
+}
+
+// metricsWriter collects samples and writes them in Prometheus text exposition format
+type metricsWriter struct {
+	order   []string
+	help    map[string]string
+	samples map[string][]string
+}
+
+func newMetricsWriter() *metricsWriter {
+	return &metricsWriter{help: map[string]string{}, samples: map[string][]string{}}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (m *metricsWriter) add(name, typ, help string, value float64, labels ...string) {
+	if _, ok := m.help[name]; !ok {
+		m.order = append(m.order, name)
+		m.help[name] = fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	var l []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		l = append(l, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	sample := name
+	if len(l) > 0 {
+		sample += "{" + strings.Join(l, ",") + "}"
+	}
+	m.samples[name] = append(m.samples[name], fmt.Sprintf("%s %v\n", sample, value))
+}
+
+func (m *metricsWriter) write(w io.Writer) {
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	}
+	for _, name := range m.order {
+		_, _ = io.WriteString(w, m.help[name])
+		for _, s := range m.samples[name] {
+			_, _ = io.WriteString(w, s)
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// target adds the metrics of the target traces, the latest trace is the last one in history,
+// the per-hop loss is calculated over all traces in history
+func (m *metricsWriter) target(name string, history [][]gotraceroute.Hop, duration time.Duration) {
+	if len(history) == 0 {
+		return
+	}
+	last := history[len(history)-1]
+	reached := len(last) > 0 && last[len(last)-1].Success && last[len(last)-1].Node.IP.Equal(last[len(last)-1].Dst.IP)
+	m.add("gotraceroute_hop_count", "gauge", "Number of hops of the latest trace.", float64(len(last)), "target", name)
+	m.add("gotraceroute_destination_reached", "gauge", "Whether the latest trace reached the destination.",
+		boolValue(reached), "target", name)
+	m.add("gotraceroute_trace_duration_seconds", "gauge", "Duration of the latest trace.", duration.Seconds(), "target", name)
+
+	stats := gotraceroute.NewPathStats()
+	for _, hops := range history {
+		stats.Add(hops)
+	}
+	nodes := map[int]string{}
+	for _, h := range last {
+		if h.Success {
+			nodes[h.Step] = h.Node.IP.String()
+			m.add("gotraceroute_hop_rtt_seconds", "gauge", "Round trip time to the hop in the latest trace.",
+				h.Elapsed.Seconds(), "target", name, "hop", fmt.Sprint(h.Step), "node", nodes[h.Step])
+		}
+	}
+	for _, h := range stats.Hops() {
+		node, ok := nodes[h.Step]
+		if !ok {
+			node = "*"
+		}
+		m.add("gotraceroute_hop_loss_ratio", "gauge", "Ratio of unanswered probes to the hop over the latest traces.",
+			h.Loss, "target", name, "hop", fmt.Sprint(h.Step), "node", node)
+		m.add("gotraceroute_hop_rate_limited", "gauge",
+			"Whether the loss at the hop is likely a control-plane ICMP rate limit rather than a forwarded-path loss.",
+			boolValue(h.LossKind == gotraceroute.LossRateLimit), "target", name, "hop", fmt.Sprint(h.Step), "node", node)
+	}
+}
+
+// counters adds the probe counters of all traces made by the exporter
+func (m *metricsWriter) counters(c *gotraceroute.ProbeCounters) {
+	m.add("gotraceroute_probes_sent_total", "counter", "Number of probe packets sent.", float64(c.ProbesSent.Load()))
+	m.add("gotraceroute_replies_received_total", "counter", "Number of replies matched to probes.", float64(c.RepliesRecv.Load()))
+	m.add("gotraceroute_probe_timeouts_total", "counter", "Number of probes left without reply.", float64(c.Timeouts.Load()))
+	m.add("gotraceroute_foreign_packets_total", "counter", "Number of received packets dropped as foreign.",
+		float64(c.ForeignPackets.Load()))
+}
@@ -5,17 +5,33 @@ import (
 	"flag"
 	"fmt"
 	"github.com/archer-v/gotraceroute"
+	"github.com/archer-v/gotraceroute/agent"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 )
 
 var (
 	options       gotraceroute.Options
-	json          bool
+	jsonOutput    bool
 	jsonCompact   bool
 	jsonFormatted bool
 	host          string
 	version       bool
+	ixpFile       string
+	asnFile       string
+	remoteAgent   string
+	targetsFile   string
+	batchOptions  gotraceroute.BatchOptions
+	rate          float64
+	prefixRate    float64
+	prefixLen     int
+	statsCount    int
+	burstSize     int
+	captureFile   string
+	outputFormat  string
 )
 
 var gitTag, gitCommit, gitBranch, buildTimestamp, versionString string
@@ -27,75 +43,174 @@ func main() {
 		versionString = fmt.Sprintf("version: %v-%v-%v, build: %v", gitTag, gitBranch, gitCommit, buildTimestamp)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "diff":
+			os.Exit(diffCommand(os.Args[2:]))
+		case "topology":
+			os.Exit(topologyCommand(os.Args[2:]))
+		case "serve":
+			os.Exit(serveCommand(os.Args[2:]))
+		case "exporter":
+			os.Exit(exporterCommand(os.Args[2:]))
+		case "agent":
+			os.Exit(agentCommand(os.Args[2:]))
+		case "controller":
+			os.Exit(controllerCommand(os.Args[2:]))
+		case "replay":
+			os.Exit(replayCommand(os.Args[2:]))
+		case "top":
+			os.Exit(topCommand(os.Args[2:]))
+		}
+	}
+
 	flag.IntVar(&options.MaxHops, "m", gotraceroute.DefaultMaxHops, `Set the max time-to-live (max number of hops) used in outgoing probe packets`)
 	flag.IntVar(&options.StartTTL, "f", gotraceroute.DefaultStartTTL, `Set the first used time-to-live, e.g. the first hop`)
 	flag.IntVar(&options.Retries, "q", 1, `Set the number of probes per hop`)
+	flag.IntVar(&options.ProbesPerHop, "Q", 0, `Send the number of probes to every hop and output results of all of them, -q isn't used then (3 by default for -o traceroute)`)
 	flag.IntVar(&options.Port, "p", gotraceroute.DefaultPort, `Set source and destination port to use`)
 	flag.DurationVar(&options.Timeout, "z", time.Millisecond*gotraceroute.DefaultTimeoutMs, "Waiting timeout in ms")
+	flag.BoolVar(&options.AdaptiveTimeout, "a", false, `Calculate the waiting timeout of every hop from RTTs of the previous hops, -z is used till the first reply`)
+	flag.DurationVar(&options.MinTimeout, "zmin", time.Millisecond*gotraceroute.DefaultMinTimeoutMs, "Min adaptive waiting timeout")
+	flag.DurationVar(&options.MaxTimeout, "zmax", time.Millisecond*gotraceroute.DefaultMaxTimeoutMs, "Max adaptive waiting timeout")
 	flag.IntVar(&options.PayloadSize, "l", 0, `Packet length`)
 	flag.BoolVar(&options.DontResolve, "n", false, "Do not resolve IP addresses to domain names")
 	flag.StringVar(&options.NetworkInterface, "i", "", `Set the network interface to use`)
-	flag.BoolVar(&jsonCompact, "j", false, "Output the result in JSON compact format")
+	flag.StringVar(&outputFormat, "o", "text", `Set the output format: text, traceroute (Linux traceroute compatible), json (JSON array), ndjson (a hop per line and the final summary), csv or tsv (a hop per row with a header row), atlas (RIPE Atlas traceroute result), warts (scamper binary trace file), mtr, mtr-json or mtr-csv (mtr report of -c traces), influx (InfluxDB line protocol) or otlp (OpenTelemetry metrics in OTLP JSON)`)
+	flag.BoolVar(&jsonCompact, "j", false, "Output the result in JSON compact format, the same as -o json")
 	flag.BoolVar(&jsonFormatted, "J", false, "Output the result in JSON pretty format")
 	flag.BoolVar(&version, "v", false, "Output an application version and exit")
+	flag.StringVar(&remoteAgent, "A", "", `Run the traceroute on the remote agent host:port (see the agent command)`)
+	flag.StringVar(&ixpFile, "x", "", `Mark hops on IXP peering LANs loaded from a PeeringDB JSON export or a "prefix name" list file`)
+	flag.StringVar(&asnFile, "asn", "", `Tag hop metrics of -o influx and otlp with AS numbers of responders loaded from a "prefix asn" list or a CAIDA pfx2as file`)
+	flag.StringVar(&captureFile, "w", "", `Write probes and all received ICMP packets to the pcap file`)
+	flag.StringVar(&targetsFile, "T", "", `Trace targets read from the file, one per line, "-" means stdin`)
+	flag.IntVar(&batchOptions.Concurrency, "P", gotraceroute.DefaultBatchConcurrency, `Set the max number of concurrent traceroutes of targets read with -T`)
+	flag.BoolVar(&batchOptions.Ordered, "O", false, `Output results of targets read with -T in the input order instead of the completion order`)
+	flag.Float64Var(&rate, "r", 0, `Set the max number of probes per second of all traceroutes, 0 means unlimited`)
+	flag.Float64Var(&prefixRate, "R", 0, `Set the max number of probes per second to destinations of the same prefix (see -L), 0 means unlimited`)
+	flag.IntVar(&prefixLen, "L", gotraceroute.DefaultRateLimitPrefixLen, `Set the destination prefix length the -R limit is applied to`)
+	flag.IntVar(&statsCount, "c", 0, `Trace the host the number of times and output per-hop statistics with the loss classified as forwarded-path loss or ICMP rate limiting`)
+	flag.IntVar(&burstSize, "b", 0, `Probe lossy hops with a burst of the number of probes to detect ICMP rate limiting, used with -c`)
 
 	flag.Parse()
-	json = jsonCompact || jsonFormatted
+	jsonOutput = jsonCompact || jsonFormatted
 	if version {
 		fmt.Println(versionString)
 		os.Exit(0)
 	}
 
 	host = flag.Arg(0)
-	if host == "" {
+	if host == "" && targetsFile == "" {
 		fmt.Println("Usage of ./gotraceroute [options] host")
+		fmt.Println("       ./gotraceroute [options] -T targets.txt")
+		fmt.Println("       ./gotraceroute diff [options] old.json new.json")
+		fmt.Println("       ./gotraceroute topology [options] trace.json...")
+		fmt.Println("       ./gotraceroute serve [options]")
+		fmt.Println("       ./gotraceroute exporter [options]")
+		fmt.Println("       ./gotraceroute agent [options]")
+		fmt.Println("       ./gotraceroute controller [options]")
+		fmt.Println("       ./gotraceroute replay [options] capture.pcap")
+		fmt.Println("       ./gotraceroute top [options] host")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	c, err := gotraceroute.Run(context.Background(), host, options)
-
-	if err != nil {
-		fmt.Println(err)
-		return
+	if ixpFile != "" {
+		var err error
+		if options.IXPDB, err = gotraceroute.LoadIXPDB(ixpFile); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	}
-
-	var lastHop gotraceroute.Hop
-	for hop := range c {
-		displayHop(hop)
-		lastHop = hop
+	if asnFile != "" {
+		db, err := gotraceroute.LoadASNDB(asnFile)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		options.ASNLookup = db
 	}
 
-	if lastHop.Step != 0 {
-		if json {
-			fmt.Printf("]")
+	if captureFile != "" {
+		f, err := os.Create(captureFile)
+		if err == nil {
+			options.Capture, err = gotraceroute.NewPcapWriter(f)
 		}
-		if lastHop.Success && lastHop.Node.IP.Equal(lastHop.Dst.IP) {
-			os.Exit(0)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 	}
 
-	os.Exit(2)
-}
+	// the batch builds the limiter of -r itself, a limiter is created here if the prefix rate is limited too
+	batchOptions.ProbesPerSecond = rate
+	if (rate > 0 && targetsFile == "") || prefixRate > 0 {
+		options.RateLimiter = gotraceroute.NewRateLimiter(rate, 1)
+		options.RateLimiter.SetPrefixLimit(prefixLen, prefixRate, 1)
+	}
+
+	if jsonOutput && outputFormat == "text" {
+		outputFormat = "json"
+	}
+	switch outputFormat {
+	case "text", "traceroute", "json", "ndjson", "csv", "tsv", "atlas", "warts", "mtr", "mtr-json", "mtr-csv", "influx", "otlp":
+	default:
+		fmt.Printf("unknown output format %v\n", outputFormat)
+		os.Exit(1)
+	}
+	jsonOutput = outputFormat == "json"
+	if outputFormat == "traceroute" && options.ProbesPerHop == 0 {
+		options.ProbesPerHop = 3
+	}
 
-func displayHop(h gotraceroute.Hop) {
-	if h.Step == 1 {
-		if json {
-			fmt.Printf("[")
-		} else {
-			fmt.Printf("traceroute to %v (%v), %v hops max, %v byte packet payload\n", host, h.Dst.IP.String(), options.MaxHops, options.PayloadSize)
+	if targetsFile != "" {
+		batchOptions.Options = options
+		os.Exit(runBatch(targetsFile))
+	}
+
+	if strings.HasPrefix(outputFormat, "mtr") && statsCount == 0 {
+		statsCount = mtrCount
+	}
+	if statsCount > 0 {
+		os.Exit(runStats(statsCount, burstSize))
+	}
+
+	// the output is completed on interrupt, so it stays valid
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var c chan gotraceroute.Hop
+	var err error
+	if remoteAgent != "" {
+		var client *agent.Client
+		if client, err = agent.Dial(remoteAgent); err == nil {
+			c, err = client.Run(ctx, host, options)
 		}
 	} else {
-		if json {
-			fmt.Printf(",")
-			if jsonFormatted {
-				fmt.Println()
-			}
+		c, err = gotraceroute.Run(ctx, host, options)
+	}
+
+	e := newEncoder(os.Stdout, host)
+	if err != nil {
+		_ = e.Close(err)
+		if outputFormat != "text" && outputFormat != "ndjson" {
+			fmt.Fprintln(os.Stderr, err)
 		}
+		stop()
+		os.Exit(1)
 	}
-	if json {
-		fmt.Print(h.StringJSON(jsonFormatted))
-	} else {
-		fmt.Println(h.StringHuman())
+
+	var hops []gotraceroute.Hop
+	for hop := range c {
+		_ = e.Encode(hop)
+		hops = gotraceroute.UpdateHops(hops, hop)
 	}
+	_ = e.Close(ctx.Err())
+	stop()
+
+	if gotraceroute.Reached(hops) {
+		os.Exit(0)
+	}
+	os.Exit(2)
 }
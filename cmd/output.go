@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"io"
+)
+
+// textEncoder writes hops in the human-readable format and the address space summary at the end
+type textEncoder struct {
+	w      io.Writer
+	target string
+	hops   []gotraceroute.Hop
+}
+
+func (e *textEncoder) Encode(h gotraceroute.Hop) error {
+	if len(e.hops) == 0 {
+		fmt.Fprintf(e.w, "traceroute to %v (%v), %v hops max, %v byte packet payload\n", e.target, h.Dst.IP.String(), options.MaxHops, options.PayloadSize)
+	}
+	e.hops = gotraceroute.UpdateHops(e.hops, h)
+	_, err := fmt.Fprintln(e.w, h.StringHuman())
+	return err
+}
+
+func (e *textEncoder) Close(err error) error {
+	if err != nil && len(e.hops) == 0 {
+		_, err = fmt.Fprintf(e.w, "traceroute to %v: %v\n", e.target, err)
+		return err
+	}
+	if err != nil {
+		_, err = fmt.Fprintln(e.w, err)
+		return err
+	}
+	if len(e.hops) > 0 {
+		_, err = fmt.Fprintln(e.w, gotraceroute.SummarizeAddrs(e.hops).String())
+	}
+	return err
+}
+
+// newSink returns the metric sink of the output format
+func newSink(w io.Writer) gotraceroute.MetricSink {
+	if outputFormat == "otlp" {
+		return gotraceroute.NewOTLPSink(w)
+	}
+	return gotraceroute.NewInfluxSink(w)
+}
+
+// newEncoder returns the encoder of the output format of the trace to target
+func newEncoder(w io.Writer, target string) gotraceroute.HopEncoder {
+	switch outputFormat {
+	case "traceroute":
+		return gotraceroute.NewTracerouteEncoder(w, target, options)
+	case "json":
+		return gotraceroute.NewJSONEncoder(w, jsonFormatted)
+	case "ndjson":
+		return gotraceroute.NewNDJSONEncoder(w, target)
+	case "csv":
+		return gotraceroute.NewCSVEncoder(w, options.ProbesPerHop)
+	case "tsv":
+		return gotraceroute.NewTSVEncoder(w, options.ProbesPerHop)
+	case "atlas":
+		return gotraceroute.NewAtlasEncoder(w, target, 0, options)
+	case "warts":
+		return gotraceroute.NewWartsEncoder(w, options)
+	case "influx", "otlp":
+		return gotraceroute.NewSinkEncoder(newSink(w), target, options)
+	default:
+		return &textEncoder{w: w, target: target}
+	}
+}
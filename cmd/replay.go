@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"os"
+)
+
+// replayCommand rebuilds traces from a pcap file and outputs them like they were traced
+func replayCommand(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var options gotraceroute.Options
+	fs.BoolVar(&options.DontResolve, "n", false, "Do not resolve IP addresses to domain names")
+	fs.IntVar(&options.Port, "p", gotraceroute.DefaultPort, "Source and destination port of the captured probes")
+	compact := fs.Bool("j", false, "Output traces in JSON compact format")
+	formatted := fs.Bool("J", false, "Output traces in JSON pretty format")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute replay [options] capture.pcap")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+
+	f, err := os.Open(fs.Arg(0)) // #nosec G304
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer f.Close()
+	traces, err := gotraceroute.ReplayPcap(f, options)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *compact || *formatted {
+		var d []byte
+		if *formatted {
+			d, _ = json.MarshalIndent(traces, "", "    ")
+		} else {
+			d, _ = json.Marshal(traces)
+		}
+		fmt.Println(string(d))
+		return 0
+	}
+	for i, t := range traces {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("traceroute to %v, flow %v\n", t.Dst, t.FlowID)
+		for _, h := range t.Hops {
+			fmt.Println(h.StringHuman())
+		}
+		fmt.Println(gotraceroute.SummarizeAddrs(t.Hops).String())
+	}
+	return 0
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceRequest is the body of the request to start a trace
+type traceRequest struct {
+	Target      string `json:"target"`
+	MaxHops     int    `json:"max_hops"`
+	StartTTL    int    `json:"start_ttl"`
+	TimeoutMs   int    `json:"timeout_ms"`
+	Retries     int    `json:"retries"`
+	PayloadSize int    `json:"payload_size"`
+	DontResolve bool   `json:"dont_resolve"`
+}
+
+const (
+	traceRunning  = "running"
+	traceFinished = "finished"
+	traceFailed   = "failed"
+)
+
+// traceResult is the state of a trace returned to clients
+type traceResult struct {
+	ID       string
+	Target   string
+	Dst      string
+	Status   string
+	Error    string `json:",omitempty"`
+	Started  time.Time
+	Finished time.Time
+	Hops     []gotraceroute.Hop
+}
+
+// trace is a trace started by the server
+type trace struct {
+	traceResult
+	client string
+	// events are hops in order of arrival including late reply updates, they are streamed to clients
+	events []gotraceroute.Hop
+	mu     sync.Mutex
+	// updated is closed and replaced on every trace update to wake up the streaming clients
+	updated chan struct{}
+}
+
+// snapshot returns the copy of the trace state and the channel closed on the next update
+func (t *trace) snapshot() (s traceResult, updated <-chan struct{}) {
+	s, _, updated = t.snapshotEvents()
+	return
+}
+
+// snapshotEvents returns the copy of the trace state, hop events and the channel closed on the next update
+func (t *trace) snapshotEvents() (s traceResult, events []gotraceroute.Hop, updated <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s = t.traceResult
+	s.Hops = append([]gotraceroute.Hop(nil), t.Hops...)
+	return s, append([]gotraceroute.Hop(nil), t.events...), t.updated
+}
+
+func (t *trace) update(f func()) {
+	t.mu.Lock()
+	f()
+	close(t.updated)
+	t.updated = make(chan struct{})
+	t.mu.Unlock()
+}
+
+// clientState is the rate limiter and the running traces counter of a client
+type clientState struct {
+	tokens  float64
+	last    time.Time
+	running int
+}
+
+type server struct {
+	options       gotraceroute.Options
+	allow         []*net.IPNet
+	deny          []*net.IPNet
+	maxConcurrent int
+	ratePerMinute float64
+	keep          time.Duration
+	// maxTimeout, maxRetries and maxPayload are the limits of client requests like options.MaxHops
+	maxTimeout time.Duration
+	maxRetries int
+	maxPayload int
+
+	mu      sync.Mutex
+	traces  map[string]*trace
+	clients map[string]*clientState
+}
+
+func serveCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "Address to listen on")
+	allow := fs.String("allow", "", "Comma separated list of destination prefixes allowed to trace, all destinations are allowed if empty")
+	deny := fs.String("deny", "", "Comma separated list of destination prefixes denied to trace, deny takes precedence over allow")
+	s := &server{traces: map[string]*trace{}, clients: map[string]*clientState{}}
+	fs.IntVar(&s.maxConcurrent, "c", 2, "Max number of concurrent traces per client")
+	fs.Float64Var(&s.ratePerMinute, "r", 10, "Max number of traces per minute per client")
+	fs.DurationVar(&s.keep, "keep", time.Hour, "Time to keep finished trace results")
+	fs.IntVar(&s.options.MaxHops, "m", gotraceroute.DefaultMaxHops, "Max time-to-live allowed for client requests")
+	fs.DurationVar(&s.maxTimeout, "max-timeout", 2*time.Second, "Max probe timeout allowed for client requests")
+	fs.IntVar(&s.maxRetries, "max-retries", 5, "Max number of probe retries allowed for client requests")
+	fs.IntVar(&s.maxPayload, "max-payload", 1472, "Max probe payload size allowed for client requests")
+	fs.StringVar(&s.options.NetworkInterface, "i", "", "Set the network interface to use")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute serve [options]")
+		fs.PrintDefaults()
+		fmt.Println(`
+Endpoints:
+  POST /traces                 start a trace, body: {"target": "example.com", "max_hops": 30, "start_ttl": 1,
+                               "timeout_ms": 200, "retries": 2, "payload_size": 0, "dont_resolve": false}
+  GET  /traces/{id}            fetch the trace result
+  GET  /traces/{id}/stream     stream hops as server-sent events (Accept: text/event-stream or ?format=sse)
+                               or as NDJSON (default)`)
+	}
+	_ = fs.Parse(args)
+
+	var err error
+	if s.allow, err = parsePrefixes(*allow); err == nil {
+		s.deny, err = parsePrefixes(*deny)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	go s.cleanup()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/traces", s.handleStart)
+	mux.HandleFunc("/traces/", s.handleTrace)
+	srv := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("looking glass is listening on %v", *listen)
+	if err = srv.ListenAndServe(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+func parsePrefixes(list string) (nets []*net.IPNet, err error) {
+	for _, p := range strings.Split(list, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if !strings.Contains(p, "/") {
+			p += "/32"
+		}
+		var n *net.IPNet
+		if _, n, err = net.ParseCIDR(p); err != nil {
+			err = fmt.Errorf("invalid prefix %v: %w", p, err)
+			return
+		}
+		nets = append(nets, n)
+	}
+	return
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns nil if the destination ip is allowed to trace
+func (s *server) allowed(ip net.IP) error {
+	if containsIP(s.deny, ip) || (len(s.allow) > 0 && !containsIP(s.allow, ip)) {
+		return fmt.Errorf("destination %v isn't allowed", ip)
+	}
+	return nil
+}
+
+var errRateLimited = errors.New("too many traces, try again later")
+
+// acquire checks the client limits and counts a new running trace of the client
+func (s *server) acquire(client string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.clients[client]
+	now := time.Now()
+	if !ok {
+		c = &clientState{tokens: s.ratePerMinute, last: now}
+		s.clients[client] = c
+	}
+	c.tokens = min(s.ratePerMinute, c.tokens+now.Sub(c.last).Minutes()*s.ratePerMinute)
+	c.last = now
+	if c.running >= s.maxConcurrent || c.tokens < 1 {
+		return errRateLimited
+	}
+	c.tokens--
+	c.running++
+	return nil
+}
+
+func (s *server) release(client string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.clients[client]; ok {
+		c.running--
+	}
+}
+
+// cleanup removes expired finished traces and idle clients
+func (s *server) cleanup() {
+	for range time.Tick(time.Minute) {
+		s.mu.Lock()
+		for id, t := range s.traces {
+			if st, _ := t.snapshot(); st.Status != traceRunning && time.Since(st.Finished) > s.keep {
+				delete(s.traces, id)
+			}
+		}
+		for id, c := range s.clients {
+			if c.running == 0 && time.Since(c.last) > time.Minute {
+				delete(s.clients, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func httpError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (s *server) handleStart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	var req traceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil || req.Target == "" {
+		httpError(w, http.StatusBadRequest, errors.New("invalid request, target is required"))
+		return
+	}
+	if req.MaxHops < 0 || req.StartTTL < 0 || req.TimeoutMs < 0 || req.Retries < 0 || req.PayloadSize < 0 {
+		httpError(w, http.StatusBadRequest, errors.New("invalid request, negative values aren't allowed"))
+		return
+	}
+
+	addrs, err := net.LookupIP(req.Target)
+	var dst net.IP
+	for _, a := range addrs {
+		if a.To4() != nil {
+			dst = a
+			break
+		}
+	}
+	if err != nil || dst == nil {
+		httpError(w, http.StatusBadRequest, fmt.Errorf("can't resolve target %v", req.Target))
+		return
+	}
+	if err = s.allowed(dst); err != nil {
+		httpError(w, http.StatusForbidden, err)
+		return
+	}
+
+	client := clientAddr(r)
+	if err = s.acquire(client); err != nil {
+		httpError(w, http.StatusTooManyRequests, err)
+		return
+	}
+
+	options := s.options
+	if req.MaxHops > 0 && req.MaxHops < options.MaxHops {
+		options.MaxHops = req.MaxHops
+	}
+	options.StartTTL = req.StartTTL
+	options.Timeout = min(time.Duration(req.TimeoutMs)*time.Millisecond, s.maxTimeout)
+	options.Retries = min(req.Retries, s.maxRetries)
+	options.PayloadSize = min(req.PayloadSize, s.maxPayload)
+	options.DontResolve = req.DontResolve
+
+	idBytes := make([]byte, 8)
+	_, _ = rand.Read(idBytes)
+	t := &trace{
+		traceResult: traceResult{
+			ID:      hex.EncodeToString(idBytes),
+			Target:  req.Target,
+			Dst:     dst.String(),
+			Status:  traceRunning,
+			Started: time.Now(),
+		},
+		client:  client,
+		updated: make(chan struct{}),
+	}
+
+	c, err := gotraceroute.Run(context.Background(), t.Dst, options)
+	if err != nil {
+		s.release(client)
+		httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.mu.Lock()
+	s.traces[t.ID] = t
+	s.mu.Unlock()
+
+	go func() {
+		for hop := range c {
+			hop.Dst.Host = t.Target
+			t.update(func() {
+				t.Hops = gotraceroute.UpdateHops(t.Hops, hop)
+				t.events = append(t.events, hop)
+			})
+		}
+		t.update(func() {
+			t.Status = traceFinished
+			t.Finished = time.Now()
+			if len(t.Hops) == 0 {
+				t.Status = traceFailed
+				t.Error = "no hops"
+			}
+		})
+		s.release(client)
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/traces/"+t.ID)
+	w.WriteHeader(http.StatusAccepted)
+	st, _ := t.snapshot()
+	_ = json.NewEncoder(w).Encode(st)
+}
+
+func (s *server) handleTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/traces/"), "/")
+	s.mu.Lock()
+	t, ok := s.traces[path[0]]
+	s.mu.Unlock()
+	if !ok {
+		httpError(w, http.StatusNotFound, errors.New("trace not found"))
+		return
+	}
+
+	switch {
+	case len(path) == 1:
+		st, _ := t.snapshot()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(st)
+	case len(path) == 2 && path[1] == "stream":
+		s.stream(w, r, t)
+	default:
+		httpError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+// stream writes the trace hops as they arrive till the trace is finished or the client has gone
+func (s *server) stream(w http.ResponseWriter, r *http.Request, t *trace) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, http.StatusInternalServerError, errors.New("streaming isn't supported"))
+		return
+	}
+	sse := r.URL.Query().Get("format") == "sse" || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+
+	write := func(event string, v interface{}) {
+		data, _ := json.Marshal(v)
+		if sse {
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+		} else {
+			fmt.Fprintf(w, "%s\n", data)
+		}
+	}
+
+	sent := 0
+	for {
+		st, events, updated := t.snapshotEvents()
+		for ; sent < len(events); sent++ {
+			write("hop", events[sent])
+		}
+		if st.Status != traceRunning {
+			st.Hops = nil
+			write("done", st)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"os"
+	"time"
+)
+
+// statsInterval is the pause between repeated traces
+const statsInterval = time.Second
+
+// mtrCount is the number of traces of the mtr report if -c isn't set, the same as mtr --report sends
+const mtrCount = 10
+
+// runStats traces the host count times, outputs the per-hop statistics and returns the exit code:
+// 0 if the destination was reached by the last trace and 2 otherwise
+func runStats(count, burst int) int {
+	switch outputFormat {
+	case "text", "json", "ndjson", "mtr", "mtr-json", "mtr-csv":
+	default:
+		fmt.Printf("output format %v isn't supported with -c\n", outputFormat)
+		return 1
+	}
+
+	stats := gotraceroute.NewPathStats()
+	start := time.Now()
+	var last []gotraceroute.Hop
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(statsInterval)
+		}
+		hops, err := gotraceroute.RunBlock(host, options)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		stats.Add(hops)
+		last = hops
+	}
+
+	if burst > 0 {
+		// hops with the loss are probed with bursts to catch the rate limiter pattern
+		for _, h := range stats.Hops() {
+			if h.Loss == 0 || h.Received == 0 {
+				continue
+			}
+			b, err := gotraceroute.BurstProbe(context.Background(), host, h.Step, burst, options)
+			if err != nil {
+				fmt.Println(err)
+				return 1
+			}
+			stats.AddBurst(b)
+		}
+	}
+
+	hops := stats.Hops()
+	report := gotraceroute.NewMTRReport(host, stats, options, start)
+	switch {
+	case outputFormat == "mtr":
+		_ = report.WriteReport(os.Stdout)
+	case outputFormat == "mtr-json":
+		_ = report.WriteJSON(os.Stdout)
+	case outputFormat == "mtr-csv":
+		_ = report.WriteCSV(os.Stdout)
+	case outputFormat == "ndjson":
+		// a hop statistics per line
+		for _, h := range hops {
+			d, _ := json.Marshal(h)
+			fmt.Println(string(d))
+		}
+	case jsonOutput:
+		var d []byte
+		if jsonFormatted {
+			d, _ = json.MarshalIndent(hops, "", "    ")
+		} else {
+			d, _ = json.Marshal(hops)
+		}
+		fmt.Println(string(d))
+	default:
+		dst := ""
+		if len(last) > 0 {
+			dst = last[0].Dst.IP.String()
+		}
+		fmt.Printf("traceroute to %v (%v), %v hops max, %v traces\n", host, dst, options.MaxHops, stats.Traces())
+		for _, h := range hops {
+			fmt.Println(h.String())
+		}
+	}
+
+	if gotraceroute.Reached(last) {
+		return 0
+	}
+	return 2
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"golang.org/x/sys/unix"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// topHistory is the number of the latest probe results kept for the sparkline of a hop
+const topHistory = 200
+
+// top display modes switched with the d key
+const (
+	topModeStats = iota
+	topModeSparkline
+	topModeResponders
+	topModes
+)
+
+// sparkLevels are the sparkline bars from the lowest RTT to the highest one
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// topHop is the live statistics of a hop
+type topHop struct {
+	step     int
+	node     net.IP
+	ixp      *gotraceroute.IXP
+	class    gotraceroute.AddrClass
+	sent     int
+	received int
+	last     time.Duration
+	best     time.Duration
+	worst    time.Duration
+	sum      float64
+	sumSq    float64
+	// changes is the number of times the responder has changed
+	changes int
+	// responders are the addresses responded at the hop with the number of replies
+	responders []net.IP
+	replies    map[string]int
+	// history is the latest RTTs, -1 is a lost probe
+	history []time.Duration
+}
+
+func (h *topHop) add(hop gotraceroute.Hop) {
+	if hop.Late {
+		// the late reply to the probe already counted as lost
+		if !hop.Success || len(h.history) == 0 || h.history[len(h.history)-1] >= 0 {
+			return
+		}
+		h.history = h.history[:len(h.history)-1]
+		h.sent--
+	}
+	h.sent++
+	if !hop.Success {
+		h.history = append(h.history, -1)
+	} else {
+		h.received++
+		h.last = hop.Elapsed
+		if h.received == 1 || hop.Elapsed < h.best {
+			h.best = hop.Elapsed
+		}
+		h.worst = max(h.worst, hop.Elapsed)
+		h.sum += float64(hop.Elapsed)
+		h.sumSq += float64(hop.Elapsed) * float64(hop.Elapsed)
+		h.history = append(h.history, hop.Elapsed)
+
+		if h.node != nil && !h.node.Equal(hop.Node.IP) {
+			h.changes++
+		}
+		h.node, h.ixp, h.class = hop.Node.IP, hop.IXP, hop.Node.Class
+		if h.replies == nil {
+			h.replies = map[string]int{}
+		}
+		if h.replies[hop.Node.IP.String()] == 0 {
+			h.responders = append(h.responders, hop.Node.IP)
+		}
+		h.replies[hop.Node.IP.String()]++
+	}
+	if len(h.history) > topHistory {
+		h.history = h.history[len(h.history)-topHistory:]
+	}
+}
+
+func (h *topHop) loss() float64 {
+	if h.sent == 0 {
+		return 0
+	}
+	return float64(h.sent-h.received) / float64(h.sent) * 100
+}
+
+func (h *topHop) avg() time.Duration {
+	if h.received == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.received))
+}
+
+func (h *topHop) stdDev() time.Duration {
+	if h.received < 2 {
+		return 0
+	}
+	n := float64(h.received)
+	return time.Duration(math.Sqrt(math.Max((h.sumSq-h.sum*h.sum/n)/(n-1), 0)))
+}
+
+// sparkline returns the bars of the latest width results scaled to the highest RTT among them, * is a lost probe
+func (h *topHop) sparkline(width int) string {
+	history := h.history
+	if len(history) > width {
+		history = history[len(history)-width:]
+	}
+	var highest time.Duration
+	for _, d := range history {
+		highest = max(highest, d)
+	}
+	var b strings.Builder
+	for _, d := range history {
+		switch {
+		case d < 0:
+			b.WriteRune('*')
+		case highest == 0:
+			b.WriteRune(sparkLevels[0])
+		default:
+			b.WriteRune(sparkLevels[int(float64(d)/float64(highest)*float64(len(sparkLevels)-1))])
+		}
+	}
+	return b.String()
+}
+
+// topEvent is a hop of the running trace or the end of the trace with its error
+type topEvent struct {
+	hop  gotraceroute.Hop
+	done bool
+	err  error
+}
+
+// topScreen is the state of the live trace view
+type topScreen struct {
+	target  string
+	dst     net.IP
+	hops    map[int]*topHop
+	rounds  int
+	mode    int
+	dns     bool
+	paused  *atomic.Bool
+	err     error
+	names   sync.Map
+	pending sync.Map
+}
+
+func (s *topScreen) reset() {
+	s.hops = map[int]*topHop{}
+	s.rounds = 0
+	s.err = nil
+}
+
+func (s *topScreen) add(e topEvent) {
+	if e.done {
+		s.rounds++
+		s.err = e.err
+		return
+	}
+	s.dst = e.hop.Dst.IP
+	h, ok := s.hops[e.hop.Step]
+	if !ok {
+		h = &topHop{step: e.hop.Step}
+		s.hops[e.hop.Step] = h
+	}
+	h.add(e.hop)
+}
+
+// name returns the host name of ip if DNS is on and the name is resolved, names are resolved in background
+func (s *topScreen) name(ip net.IP) string {
+	if !s.dns {
+		return ip.String()
+	}
+	key := ip.String()
+	if n, ok := s.names.Load(key); ok {
+		return n.(string)
+	}
+	if _, loading := s.pending.LoadOrStore(key, true); !loading {
+		go func() {
+			name := key
+			if names, err := net.LookupAddr(key); err == nil && len(names) > 0 {
+				name = strings.TrimSuffix(names[0], ".")
+			}
+			s.names.Store(key, name)
+		}()
+	}
+	return key
+}
+
+// render returns the screen content fitted to the terminal size
+func (s *topScreen) render(width, height int) string {
+	lines := []string{
+		fmt.Sprintf("gotraceroute top: %v (%v)  %v traces  %v", s.target, s.dst, s.rounds, time.Now().Format(time.RFC3339)),
+		"Keys: n - DNS on/off, d - display mode, r - reset counters, p - pause, q - quit",
+	}
+	status := ""
+	if s.paused.Load() {
+		status = "[paused] "
+	}
+	if s.err != nil {
+		status += s.err.Error()
+	}
+	lines = append(lines, status, "")
+
+	steps := make([]int, 0, len(s.hops))
+	last := 0
+	for step, h := range s.hops {
+		steps = append(steps, step)
+		if h.node != nil && h.node.Equal(s.dst) && (last == 0 || step < last) {
+			last = step
+		}
+	}
+	first, end := math.MaxInt, 0
+	for _, step := range steps {
+		first, end = min(first, step), max(end, step)
+	}
+	if last != 0 {
+		end = last
+	}
+
+	const nodeWidth = 40
+	switch s.mode {
+	case topModeStats:
+		lines = append(lines, fmt.Sprintf("%-*s %6s %5s %7s %7s %7s %7s %7s %4s", nodeWidth+5, " Host",
+			"Loss%", "Snt", "Last", "Avg", "Best", "Wrst", "StDev", "Chg"))
+	case topModeSparkline:
+		lines = append(lines, fmt.Sprintf("%-*s RTT of the latest probes (* lost)", nodeWidth+5, " Host"))
+	case topModeResponders:
+		lines = append(lines, fmt.Sprintf("%-*s Responders (replies)", nodeWidth+5, " Host"))
+	}
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	for step := first; step <= end; step++ {
+		h, ok := s.hops[step]
+		if !ok {
+			h = &topHop{step: step}
+		}
+		node := "???"
+		if h.node != nil {
+			node = s.name(h.node)
+			if h.class != "" && !h.class.IsPublic() {
+				node += fmt.Sprintf(" [%v]", h.class)
+			}
+			if h.ixp != nil {
+				node += fmt.Sprintf(" [%v]", h.ixp.String())
+			}
+		}
+		prefix := fmt.Sprintf("%3d. %-*.*s", step, nodeWidth, nodeWidth, node)
+		switch s.mode {
+		case topModeStats:
+			lines = append(lines, fmt.Sprintf("%s %5.1f%% %5d %7.2f %7.2f %7.2f %7.2f %7.2f %4d", prefix, h.loss(), h.sent,
+				ms(h.last), ms(h.avg()), ms(h.best), ms(h.worst), ms(h.stdDev()), h.changes))
+		case topModeSparkline:
+			lines = append(lines, prefix+" "+h.sparkline(max(width-len(prefix)-1, 1)))
+		case topModeResponders:
+			responders := make([]string, 0, len(h.responders))
+			for _, ip := range h.responders {
+				responders = append(responders, fmt.Sprintf("%v (%d)", s.name(ip), h.replies[ip.String()]))
+			}
+			lines = append(lines, prefix+" "+strings.Join(responders, ", "))
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, l := range lines {
+		if r := []rune(l); len(r) > width {
+			l = string(r[:width])
+		}
+		b.WriteString(l + "\x1b[K")
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+	return b.String()
+}
+
+// terminalSize returns the size of the terminal or 80x24 if it's unknown
+func terminalSize(fd int) (width, height int) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// makeRaw switches the terminal to read keys without echo and line buffering and returns the function restoring it,
+// signals are still generated, so Ctrl-C works
+func makeRaw(fd int) (restore func(), err error) {
+	old, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return
+	}
+	raw := *old
+	raw.Lflag &^= unix.ECHO | unix.ICANON
+	raw.Cc[unix.VMIN], raw.Cc[unix.VTIME] = 1, 0
+	if err = unix.IoctlSetTermios(fd, unix.TCSETS, &raw); err != nil {
+		return
+	}
+	return func() { _ = unix.IoctlSetTermios(fd, unix.TCSETS, old) }, nil
+}
+
+// topCommand traces the host continuously and shows live per-hop statistics full screen like mtr does
+//
+//nolint:gocyclo
+func topCommand(args []string) int {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	var options gotraceroute.Options
+	fs.IntVar(&options.MaxHops, "m", gotraceroute.DefaultMaxHops, `Set the max time-to-live (max number of hops) used in outgoing probe packets`)
+	fs.IntVar(&options.StartTTL, "f", gotraceroute.DefaultStartTTL, `Set the first used time-to-live, e.g. the first hop`)
+	fs.IntVar(&options.Port, "p", gotraceroute.DefaultPort, `Set source and destination port to use`)
+	fs.DurationVar(&options.Timeout, "z", time.Millisecond*gotraceroute.DefaultTimeoutMs, "Waiting timeout in ms")
+	fs.IntVar(&options.PayloadSize, "l", 0, `Packet length`)
+	fs.StringVar(&options.NetworkInterface, "i", "", `Set the network interface to use`)
+	noDNS := fs.Bool("n", false, "Start with DNS resolution off, it's toggled with the n key")
+	interval := fs.Duration("I", statsInterval, "Pause between traces")
+	ixpFile := fs.String("x", "", `Mark hops on IXP peering LANs loaded from a PeeringDB JSON export or a "prefix name" list file`)
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute top [options] host")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 1
+	}
+	target := fs.Arg(0)
+	// names are resolved by the screen, so DNS can be toggled without restarting traces
+	options.DontResolve = true
+	options.Retries = 1
+	if *ixpFile != "" {
+		var err error
+		if options.IXPDB, err = gotraceroute.LoadIXPDB(*ixpFile); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	fd := int(os.Stdin.Fd())
+	restore, err := makeRaw(fd)
+	if err != nil {
+		fmt.Println("top requires a terminal:", err)
+		return 1
+	}
+	defer restore()
+	// the alternate screen is used and the cursor is hidden till exit
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	paused := &atomic.Bool{}
+	events := make(chan topEvent)
+	go func() {
+		for ctx.Err() == nil {
+			if !paused.Load() {
+				c, err := gotraceroute.Run(ctx, target, options)
+				for c != nil {
+					hop, ok := <-c
+					if !ok {
+						break
+					}
+					select {
+					case events <- topEvent{hop: hop}:
+					case <-ctx.Done():
+					}
+				}
+				select {
+				case events <- topEvent{done: true, err: err}:
+				case <-ctx.Done():
+				}
+			}
+			select {
+			case <-time.After(*interval):
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if n, err := os.Stdin.Read(buf); err != nil || n == 0 {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+
+	screen := &topScreen{target: target, dns: !*noDNS, paused: paused}
+	screen.reset()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case e := <-events:
+			screen.add(e)
+		case k, ok := <-keys:
+			if !ok {
+				return 0
+			}
+			switch k {
+			case 'q', 'Q':
+				return 0
+			case 'n':
+				screen.dns = !screen.dns
+			case 'd':
+				screen.mode = (screen.mode + 1) % topModes
+			case 'r':
+				screen.reset()
+			case 'p', ' ':
+				paused.Store(!paused.Load())
+			}
+		case <-ticker.C:
+		}
+		fmt.Print(screen.render(terminalSize(fd)))
+	}
+}
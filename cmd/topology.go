@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"net"
+	"os"
+)
+
+// topologyCommand merges traces saved in JSON format or RIPE Atlas results into a topology graph and writes it to stdout
+func topologyCommand(args []string) int {
+	fs := flag.NewFlagSet("topology", flag.ExitOnError)
+	format := fs.String("o", "dot", "Output format: dot, graphml or json")
+	aliases := fs.Bool("a", false, "Resolve interface aliases by probing the nodes and draw a router level graph (raw socket access required)")
+	networkInterface := fs.String("i", "", "Set the network interface to use for alias resolution probes")
+	fs.Usage = func() {
+		fmt.Println("Usage of ./gotraceroute topology [options] trace.json...")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+
+	topo := gotraceroute.NewTopology()
+	for _, f := range fs.Args() {
+		traces, err := readTraces(f)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		for _, hops := range traces {
+			topo.Add(hops)
+		}
+	}
+
+	if *aliases {
+		var candidates []net.IP
+		for _, n := range topo.Nodes() {
+			if !n.Anonymous {
+				candidates = append(candidates, n.IP)
+			}
+		}
+		routers, err := gotraceroute.ResolveAliases(context.Background(), candidates,
+			gotraceroute.AliasOptions{NetworkInterface: *networkInterface})
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		topo.MergeAliases(routers)
+	}
+
+	var err error
+	switch *format {
+	case "dot":
+		err = topo.WriteDOT(os.Stdout)
+	case "graphml":
+		err = topo.WriteGraphML(os.Stdout)
+	case "json":
+		err = topo.WriteJSON(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,224 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"time"
+)
+
+// DecodeHops reads hops saved by this tool: a JSON array of hops or a stream of JSON hop objects (NDJSON).
+// Late updates replace hops of the same step and the NDJSON summary record is skipped
+func DecodeHops(r io.Reader) (hops []Hop, err error) {
+	br := bufio.NewReader(r)
+	first, err := peekJSON(br)
+	if err != nil || first == 0 {
+		return
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var all []Hop
+		if err = dec.Decode(&all); err != nil {
+			return
+		}
+		for _, hop := range all {
+			hops = addDecodedHop(hops, hop)
+		}
+		return
+	}
+	for {
+		var hop Hop
+		if err = dec.Decode(&hop); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		if hop.Step != 0 {
+			hops = addDecodedHop(hops, hop)
+		}
+	}
+}
+
+// peekJSON skips the leading white space and returns the first byte of the JSON input without consuming it,
+// 0 is returned if the input is empty
+func peekJSON(br *bufio.Reader) (first byte, err error) {
+	for {
+		if first, err = br.ReadByte(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return 0, err
+		}
+		if first != ' ' && first != '\t' && first != '\r' && first != '\n' {
+			return first, br.UnreadByte()
+		}
+	}
+}
+
+// DecodeTraces reads traces saved by this tool (see DecodeHops) or RIPE Atlas traceroute results (see DecodeAtlas),
+// the input of this tool is a single trace while Atlas results may have many of them
+func DecodeTraces(r io.Reader) (traces [][]Hop, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	if isAtlas(data) {
+		var results []AtlasResult
+		if results, err = DecodeAtlas(bytes.NewReader(data)); err != nil {
+			return
+		}
+		for _, a := range results {
+			traces = append(traces, a.Hops())
+		}
+		return
+	}
+	hops, err := DecodeHops(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	return [][]Hop{hops}, nil
+}
+
+// isAtlas returns true if the first JSON object of data, or the first element of the array, is an Atlas result
+func isAtlas(data []byte) bool {
+	var first json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&first); err != nil {
+		return false
+	}
+	var elements []json.RawMessage
+	if json.Unmarshal(first, &elements) == nil {
+		if len(elements) == 0 {
+			return false
+		}
+		first = elements[0]
+	}
+	var probe struct {
+		PrbID *int   `json:"prb_id"`
+		Type  string `json:"type"`
+	}
+	return json.Unmarshal(first, &probe) == nil && probe.PrbID != nil && probe.Type == "traceroute"
+}
+
+func addDecodedHop(hops []Hop, hop Hop) []Hop {
+	if hop.Late {
+		return UpdateHops(hops, hop)
+	}
+	return append(hops, hop)
+}
+
+// JSONEncoder writes hops as a JSON array, the output is a valid JSON document whatever hops are written
+// if Close is called: the array is opened with the first hop and closed by Close, even if there are no hops.
+// Late updates are written as separate elements, DecodeHops merges them
+type JSONEncoder struct {
+	w      io.Writer
+	indent bool
+	count  int
+}
+
+// NewJSONEncoder returns the encoder of a JSON array of hops, indent makes the output pretty
+func NewJSONEncoder(w io.Writer, indent bool) *JSONEncoder {
+	return &JSONEncoder{w: w, indent: indent}
+}
+
+// Encode writes the hop as the next element of the array
+func (e *JSONEncoder) Encode(h Hop) (err error) {
+	sep := ","
+	if e.indent {
+		sep = ",\n"
+	}
+	if e.count == 0 {
+		sep = "["
+	}
+	if _, err = io.WriteString(e.w, sep+h.StringJSON(e.indent)); err == nil {
+		e.count++
+	}
+	return
+}
+
+// Close closes the array
+func (e *JSONEncoder) Close(_ error) (err error) {
+	if e.count == 0 {
+		_, err = io.WriteString(e.w, "[]\n")
+	} else {
+		_, err = io.WriteString(e.w, "]\n")
+	}
+	return
+}
+
+// TraceSummary is the final record of the NDJSON output
+type TraceSummary struct {
+	Target string
+	Dst    net.IP `json:",omitempty"`
+	// Hops is the number of hops of the trace
+	Hops     int
+	Reached  bool
+	Started  time.Time
+	Duration time.Duration
+	Error    string `json:",omitempty"`
+}
+
+// newTraceSummary returns the summary of the trace to target started at the time started,
+// the trace may be encoded after it's finished, e.g. a batch result, so the start time and the duration
+// are taken from hops if they are known
+func newTraceSummary(target string, hops []Hop, started time.Time, err error) TraceSummary {
+	s := TraceSummary{Target: target, Hops: len(hops), Started: started, Reached: Reached(hops)}
+	if len(hops) > 0 {
+		first, last := hops[0], hops[len(hops)-1]
+		s.Dst = last.Dst.IP
+		if !first.Sent.IsZero() && !last.Sent.IsZero() {
+			s.Started = first.Sent
+			s.Duration = last.Sent.Add(last.Elapsed).Sub(first.Sent)
+		}
+	}
+	if s.Duration == 0 {
+		s.Duration = time.Since(started)
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s
+}
+
+// NDJSONEncoder writes every hop as a JSON object on its own line as soon as it arrives,
+// the writer is flushed after every line if it has the Flush method.
+// Close writes the final line {"Summary": {...}} with the TraceSummary
+type NDJSONEncoder struct {
+	w       io.Writer
+	target  string
+	started time.Time
+	hops    []Hop
+}
+
+// NewNDJSONEncoder returns the NDJSON encoder of the trace to target
+func NewNDJSONEncoder(w io.Writer, target string) *NDJSONEncoder {
+	return &NDJSONEncoder{w: w, target: target, started: time.Now()}
+}
+
+func (e *NDJSONEncoder) writeLine(v interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = e.w.Write(append(d, '\n')); err != nil {
+		return err
+	}
+	if f, ok := e.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Encode writes the hop line
+func (e *NDJSONEncoder) Encode(h Hop) error {
+	e.hops = UpdateHops(e.hops, h)
+	return e.writeLine(h)
+}
+
+// Close writes the summary line, err is reported in the summary
+func (e *NDJSONEncoder) Close(err error) error {
+	return e.writeLine(struct{ Summary TraceSummary }{newTraceSummary(e.target, e.hops, e.started, err)})
+}
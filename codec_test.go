@@ -0,0 +1,88 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	for _, indent := range []bool{false, true} {
+		var b strings.Builder
+		e := NewJSONEncoder(&b, indent)
+		_ = e.Close(errors.New("failed"))
+		if b.String() != "[]\n" {
+			t.Errorf("expected an empty array, got %q", b.String())
+		}
+
+		b.Reset()
+		e = NewJSONEncoder(&b, indent)
+		// the trace starting at the ttl 3 is a valid array too
+		hops := testTrace("*", "*", "*", "192.0.2.4")[2:]
+		for _, h := range hops {
+			_ = e.Encode(h)
+		}
+		late := testTrace("*", "*", "10.0.0.3")[2]
+		late.Late = true
+		_ = e.Encode(late)
+		_ = e.Close(nil)
+
+		var decoded []Hop
+		if err := json.Unmarshal([]byte(b.String()), &decoded); err != nil || len(decoded) != 3 {
+			t.Errorf("invalid JSON array %q: %v", b.String(), err)
+		}
+		if d, err := DecodeHops(strings.NewReader(b.String())); err != nil || len(d) != 2 || !d[0].Late {
+			t.Errorf("unexpected decoded hops %v: %v", d, err)
+		}
+	}
+}
+
+func TestNDJSONEncoder(t *testing.T) {
+	var b strings.Builder
+	e := NewNDJSONEncoder(&b, "example.com")
+	hops := testTrace("10.0.0.1", "*")
+	for _, h := range hops {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(errors.New("cancelled"))
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 hop lines and the summary, got %q", b.String())
+	}
+	var summary struct{ Summary TraceSummary }
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if s := summary.Summary; s.Target != "example.com" || s.Hops != 2 || s.Reached || s.Error != "cancelled" {
+		t.Errorf("unexpected summary %+v", s)
+	}
+	if d, err := DecodeHops(strings.NewReader(b.String())); err != nil || len(d) != 2 {
+		t.Errorf("unexpected decoded hops %v: %v", d, err)
+	}
+}
+
+func TestDecodeLateProbeUpdate(t *testing.T) {
+	// the late reply to the second probe of the hop answered by the first probe is delivered as an update
+	hops := testProbeTrace("10.0.0.1,*", "192.0.2.9")
+	update := hops[0]
+	if !update.updateLate(testTrace("10.0.0.2")[0], 1) || !update.Late {
+		t.Fatalf("expected a late update of the hop, got %+v", update)
+	}
+
+	var b strings.Builder
+	e := NewNDJSONEncoder(&b, "example.com")
+	for _, h := range append(hops, update) {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(nil)
+	d, err := DecodeHops(strings.NewReader(b.String()))
+	if err != nil || len(d) != 2 {
+		t.Fatalf("unexpected decoded hops %v: %v", d, err)
+	}
+	if p := d[0].Probes; len(p) != 2 || !p[1].Success || !p[1].Node.IP.Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("unexpected probes of the updated hop %+v", p)
+	}
+}
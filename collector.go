@@ -0,0 +1,51 @@
+package gotraceroute
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Collector receives probe level events from a running traceroute.
+// Implementations must be safe for concurrent use, as the same collector can be shared between traceroutes
+type Collector interface {
+	// ProbeSent is called when a probe packet with the ttl is sent to the dst address
+	ProbeSent(dst net.IP, ttl int)
+	// ReplyReceived is called when the reply to the probe with the ttl is received
+	ReplyReceived(dst net.IP, ttl int, rtt time.Duration)
+	// Timeout is called when no reply to the probe with the ttl was received in time
+	Timeout(dst net.IP, ttl int)
+	// ForeignPacket is called when a received packet doesn't match the probe and is dropped
+	ForeignPacket(dst net.IP)
+}
+
+type nopCollector struct{}
+
+func (nopCollector) ProbeSent(net.IP, int)                    {}
+func (nopCollector) ReplyReceived(net.IP, int, time.Duration) {}
+func (nopCollector) Timeout(net.IP, int)                      {}
+func (nopCollector) ForeignPacket(net.IP)                     {}
+
+// ProbeCounters is a Collector counting probe events of all traceroutes it's assigned to
+type ProbeCounters struct {
+	ProbesSent     atomic.Uint64
+	RepliesRecv    atomic.Uint64
+	Timeouts       atomic.Uint64
+	ForeignPackets atomic.Uint64
+}
+
+func (c *ProbeCounters) ProbeSent(net.IP, int) {
+	c.ProbesSent.Add(1)
+}
+
+func (c *ProbeCounters) ReplyReceived(net.IP, int, time.Duration) {
+	c.RepliesRecv.Add(1)
+}
+
+func (c *ProbeCounters) Timeout(net.IP, int) {
+	c.Timeouts.Add(1)
+}
+
+func (c *ProbeCounters) ForeignPacket(net.IP) {
+	c.ForeignPackets.Add(1)
+}
@@ -0,0 +1,223 @@
+// Package controller runs multi vantage point measurement campaigns: a list of targets is traced
+// from a fleet of registered agents, failed measurements are retried and results are collected
+// in a common format.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/archer-v/gotraceroute"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+const DefaultRetries = 2
+const DefaultRetryDelay = 5 * time.Second
+const DefaultAgentConcurrency = 4
+
+// Agent runs traceroutes on behalf of the controller
+type Agent interface {
+	RunBlock(ctx context.Context, dest string, options gotraceroute.Options) ([]gotraceroute.Hop, error)
+}
+
+// Campaign is a set of targets to trace from the agents
+type Campaign struct {
+	Name    string
+	Targets []string
+	// Agents are the names of agents to trace from, all registered agents are used if empty
+	Agents  []string
+	Options gotraceroute.Options
+}
+
+// Result is a result of one measurement of the campaign
+type Result struct {
+	Campaign string
+	Agent    string
+	Target   string
+	Started  time.Time
+	Finished time.Time
+	// Attempts is the number of attempts made to get the result
+	Attempts int
+	Error    string `json:",omitempty"`
+	Hops     []gotraceroute.Hop
+}
+
+// Options type
+type Options struct {
+	// Retries is the number of times a failed measurement is retried on the same agent
+	Retries int
+	// RetryDelay is the delay before the measurement is retried
+	RetryDelay time.Duration
+	// AgentConcurrency is the max number of concurrent measurements per agent
+	AgentConcurrency int
+	// Store, if set, keeps the successful results, the store target is "agent/target"
+	Store *gotraceroute.Store
+}
+
+func (o *Options) retries() int {
+	if o.Retries == 0 {
+		o.Retries = DefaultRetries
+	}
+	return o.Retries
+}
+
+func (o *Options) retryDelay() time.Duration {
+	if o.RetryDelay == 0 {
+		o.RetryDelay = DefaultRetryDelay
+	}
+	return o.RetryDelay
+}
+
+func (o *Options) agentConcurrency() int {
+	if o.AgentConcurrency == 0 {
+		o.AgentConcurrency = DefaultAgentConcurrency
+	}
+	return o.AgentConcurrency
+}
+
+// Controller keeps the registry of agents and runs campaigns on them, it should be created with New
+type Controller struct {
+	options Options
+	mu      sync.RWMutex
+	agents  map[string]Agent
+}
+
+// New returns a controller without registered agents
+func New(options Options) *Controller {
+	return &Controller{options: options, agents: map[string]Agent{}}
+}
+
+// Register adds the agent to the fleet, an agent with the same name is replaced
+func (c *Controller) Register(name string, agent Agent) {
+	c.mu.Lock()
+	c.agents[name] = agent
+	c.mu.Unlock()
+}
+
+// Unregister removes the agent from the fleet
+func (c *Controller) Unregister(name string) {
+	c.mu.Lock()
+	delete(c.agents, name)
+	c.mu.Unlock()
+}
+
+// Agents returns the names of the registered agents
+func (c *Controller) Agents() (names []string) {
+	c.mu.RLock()
+	for n := range c.agents {
+		names = append(names, n)
+	}
+	c.mu.RUnlock()
+	sort.Strings(names)
+	return
+}
+
+type job struct {
+	agent  string
+	target string
+}
+
+// Run traces every campaign target from every campaign agent and returns the results ordered by agent and target.
+// A failed measurement is retried, the result contains the error if all attempts failed.
+// Run returns an error only if the campaign can't be started
+func (c *Controller) Run(ctx context.Context, campaign Campaign) (results []Result, err error) {
+	names := campaign.Agents
+	if len(names) == 0 {
+		names = c.Agents()
+	}
+	if len(names) == 0 {
+		return nil, errors.New("no agents to run the campaign")
+	}
+	agents := make(map[string]Agent, len(names))
+	c.mu.RLock()
+	for _, n := range names {
+		if agents[n] = c.agents[n]; agents[n] == nil {
+			err = fmt.Errorf("agent %v isn't registered", n)
+		}
+	}
+	c.mu.RUnlock()
+	if err != nil {
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		jobs := make(chan job)
+		for i := 0; i < c.options.agentConcurrency(); i++ {
+			wg.Add(1)
+			go func(agent Agent) {
+				defer wg.Done()
+				for j := range jobs {
+					r := c.measure(ctx, campaign, agent, j)
+					mu.Lock()
+					results = append(results, r)
+					mu.Unlock()
+				}
+			}(agents[name])
+		}
+		go func(name string, jobs chan<- job) {
+			for _, t := range campaign.Targets {
+				jobs <- job{agent: name, target: t}
+			}
+			close(jobs)
+		}(name, jobs)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Agent != results[j].Agent {
+			return results[i].Agent < results[j].Agent
+		}
+		return results[i].Target < results[j].Target
+	})
+	return
+}
+
+// measure traces the target from the agent retrying on errors
+func (c *Controller) measure(ctx context.Context, campaign Campaign, agent Agent, j job) (r Result) {
+	r = Result{Campaign: campaign.Name, Agent: j.agent, Target: j.target, Started: time.Now()}
+	for r.Attempts <= c.options.retries() {
+		if r.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.options.retryDelay()):
+			}
+		}
+		if ctx.Err() != nil {
+			r.Error = ctx.Err().Error()
+			break
+		}
+		r.Attempts++
+		hops, err := agent.RunBlock(ctx, j.target, campaign.Options)
+		if err == nil {
+			r.Hops = hops
+			r.Error = ""
+			break
+		}
+		r.Error = err.Error()
+	}
+	r.Finished = time.Now()
+
+	if r.Error == "" && c.options.Store != nil {
+		if _, err := c.options.Store.Add(j.agent+"/"+j.target, r.Hops); err != nil {
+			r.Error = fmt.Sprintf("can't store the result: %v", err)
+		}
+	}
+	return
+}
+
+// WriteResults writes the results as JSON lines, one result per line
+func WriteResults(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/archer-v/gotraceroute"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeAgent struct {
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+func (a *fakeAgent) RunBlock(_ context.Context, dest string, _ gotraceroute.Options) ([]gotraceroute.Hop, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failures[dest] > 0 {
+		a.failures[dest]--
+		return nil, errors.New("agent is unavailable")
+	}
+	return []gotraceroute.Hop{{Success: true, Step: 1}}, nil
+}
+
+func TestController(t *testing.T) {
+	c := New(Options{Retries: 1, RetryDelay: time.Millisecond})
+	c.Register("b", &fakeAgent{failures: map[string]int{"t1": 1}})
+	c.Register("a", &fakeAgent{failures: map[string]int{"t2": 2}})
+
+	results, err := c.Run(context.Background(), Campaign{Name: "test", Targets: []string{"t2", "t1"}})
+	if err != nil {
+		t.Fatalf("campaign failed: %v", err)
+	}
+	expected := []struct {
+		agent, target string
+		attempts      int
+		failed        bool
+	}{{"a", "t1", 1, false}, {"a", "t2", 2, true}, {"b", "t1", 2, false}, {"b", "t2", 1, false}}
+	if len(results) != len(expected) {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.Agent != e.agent || r.Target != e.target || r.Attempts != e.attempts || (r.Error != "") != e.failed {
+			t.Errorf("unexpected result %d: %+v", i, r)
+		}
+	}
+
+	var b bytes.Buffer
+	if err = WriteResults(&b, results); err != nil || bytes.Count(b.Bytes(), []byte("\n")) != 4 {
+		t.Errorf("WriteResults failed: %v", err)
+	}
+
+	if _, err = c.Run(context.Background(), Campaign{Targets: []string{"t1"}, Agents: []string{"unknown"}}); err == nil {
+		t.Errorf("expected error on unknown agent")
+	}
+}
+
+func TestLocalAgent(t *testing.T) {
+	client, stop, err := StartLocalAgent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	c := New(Options{})
+	c.Register("local", client)
+	results, err := c.Run(context.Background(), Campaign{Targets: []string{"127.0.0.1"}, Options: gotraceroute.Options{MaxHops: 3}})
+	if err != nil || len(results) != 1 || results[0].Error != "" || len(results[0].Hops) == 0 {
+		t.Errorf("unexpected local agent result: %+v (%v)", results, err)
+	}
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"github.com/archer-v/gotraceroute/agent"
+	"google.golang.org/grpc"
+	"net"
+)
+
+// StartLocalAgent starts an in-process gRPC trace agent on the loopback interface and returns the client connected to it,
+// so the whole system can run on one machine. stop shuts down the agent and closes the client
+func StartLocalAgent() (client *agent.Client, stop func(), err error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return
+	}
+	srv := grpc.NewServer()
+	(&agent.Server{}).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+
+	if client, err = agent.Dial(lis.Addr().String()); err != nil {
+		srv.Stop()
+		return
+	}
+	stop = func() {
+		_ = client.Close()
+		srv.Stop()
+	}
+	return
+}
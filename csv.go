@@ -0,0 +1,83 @@
+package gotraceroute
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// hopColumns is the stable column order of Hop.Fields() in CSV output
+var hopColumns = []string{
+	"step", "success", "nodeip", "nodehost", "nodeclass", "elapsed", "late",
+	"srcip", "srchost", "dstip", "dsthost", "id", "sent", "received",
+	"ixp", "ixpmemberasn", "ixpmember",
+}
+
+// CSVColumns returns the columns of the CSV output of hops probed probes times,
+// columns of every probe are added if there are more than 1 probe
+func CSVColumns(probes int) []string {
+	columns := append([]string(nil), hopColumns...)
+	if probes > 1 {
+		for i := 1; i <= probes; i++ {
+			n := fmt.Sprintf("probe%d", i)
+			columns = append(columns, n+"success", n+"nodeip", n+"elapsed")
+		}
+	}
+	return columns
+}
+
+// CSVEncoder writes hops as CSV or TSV rows of Hop.Fields() values in the stable column order with a header row,
+// fields missing in a hop, like IXP fields, are left empty. Late updates are written as separate rows
+type CSVEncoder struct {
+	w       *csv.Writer
+	columns []string
+	header  bool
+}
+
+// NewCSVEncoder returns the comma separated values encoder of hops probed probes times (see Options.ProbesPerHop)
+func NewCSVEncoder(w io.Writer, probes int) *CSVEncoder {
+	return &CSVEncoder{w: csv.NewWriter(w), columns: CSVColumns(probes)}
+}
+
+// NewTSVEncoder returns the tab separated values encoder of hops probed probes times (see Options.ProbesPerHop)
+func NewTSVEncoder(w io.Writer, probes int) *CSVEncoder {
+	e := NewCSVEncoder(w, probes)
+	e.w.Comma = '\t'
+	return e
+}
+
+func (e *CSVEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(e.columns)
+}
+
+// Encode writes the hop row, the header row is written before the first hop
+func (e *CSVEncoder) Encode(h Hop) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	fields := h.Fields()
+	record := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		if v, ok := fields[c]; ok {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	if err := e.w.Write(record); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// Close writes the header row if there were no hops and flushes the output
+func (e *CSVEncoder) Close(_ error) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
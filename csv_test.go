@@ -0,0 +1,59 @@
+package gotraceroute
+
+import (
+	"encoding/csv"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	hops := testTrace("10.0.0.1", "*")
+	hops[0].IXP = &IXP{Name: "DE-CIX", MemberASN: 64500}
+	hops[1].Probes = []HopProbe{{}, {Success: true, Node: Addr{IP: net.ParseIP("10.0.0.2")}, Elapsed: 12 * time.Millisecond}}
+
+	for _, tsv := range []bool{false, true} {
+		var b strings.Builder
+		e := NewCSVEncoder(&b, 2)
+		if tsv {
+			e = NewTSVEncoder(&b, 2)
+		}
+		for _, h := range hops {
+			_ = e.Encode(h)
+		}
+		_ = e.Close(nil)
+
+		r := csv.NewReader(strings.NewReader(b.String()))
+		if tsv {
+			r.Comma = '\t'
+		}
+		records, err := r.ReadAll()
+		if err != nil || len(records) != 3 {
+			t.Fatalf("unexpected output %q: %v", b.String(), err)
+		}
+		row := map[string][]string{}
+		for i, c := range records[0] {
+			row[c] = []string{records[1][i], records[2][i]}
+		}
+		if strings.Join(records[0][:3], ",") != "step,success,nodeip" || len(records[0]) != len(hopColumns)+6 {
+			t.Errorf("unexpected header %v", records[0])
+		}
+		if row["step"][1] != "2" || row["nodeip"][0] != "10.0.0.1" || row["ixp"][0] != "DE-CIX" || row["ixp"][1] != "" ||
+			row["probe2nodeip"][1] != "10.0.0.2" || row["probe2elapsed"][1] != "12" || row["probe1success"][1] != "false" {
+			t.Errorf("unexpected rows %v", row)
+		}
+		// unknown addresses and times of the * hop and the lost probe are empty
+		for _, c := range []string{"nodeip", "srcip", "dstip", "sent", "received", "probe1nodeip"} {
+			if row[c][1] != "" {
+				t.Errorf("expected empty %v of the lost hop, got %q", c, row[c][1])
+			}
+		}
+	}
+
+	var b strings.Builder
+	_ = NewCSVEncoder(&b, 1).Close(nil)
+	if b.String() != strings.Join(hopColumns, ",")+"\n" {
+		t.Errorf("expected the header only, got %q", b.String())
+	}
+}
@@ -0,0 +1,229 @@
+package gotraceroute
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HopDiffKind describes how a hop differs between two traces
+type HopDiffKind string
+
+const (
+	// HopUnchanged means the hop has the same responders in both traces
+	HopUnchanged HopDiffKind = "unchanged"
+	// HopAlternate means the responders differ but overlap, that is usually a load-balanced path
+	HopAlternate HopDiffKind = "alternate"
+	// HopSilent means the hop didn't respond in one or both traces, so it can't be compared
+	HopSilent HopDiffKind = "silent"
+	// HopChanged means the hop responders are completely different
+	HopChanged HopDiffKind = "changed"
+	// HopAdded means the hop exists in the second trace only
+	HopAdded HopDiffKind = "added"
+	// HopRemoved means the hop exists in the first trace only
+	HopRemoved HopDiffKind = "removed"
+)
+
+// HopDiff is a difference of one hop between two traces
+type HopDiff struct {
+	Kind HopDiffKind
+	// StepA and StepB are the hop steps in the first and the second trace, 0 if the hop is absent in the trace
+	StepA int
+	StepB int
+	// NodesA and NodesB are the hop responders in the first and the second trace
+	NodesA []net.IP
+	NodesB []net.IP
+	// RTTA and RTTB are the minimal hop round trip times in the first and the second trace
+	RTTA time.Duration
+	RTTB time.Duration
+	// RTTDelta is RTTB - RTTA, it's filled in only if the hop responded in both traces
+	RTTDelta time.Duration
+}
+
+func (d *HopDiff) String() string {
+	step := fmt.Sprintf("%d", d.StepB)
+	switch {
+	case d.StepB == 0:
+		step = fmt.Sprintf("%d", d.StepA)
+	case d.StepA != 0 && d.StepA != d.StepB:
+		step = fmt.Sprintf("%d/%d", d.StepA, d.StepB)
+	}
+	s := fmt.Sprintf("%-5s %-9s %v -> %v", step, d.Kind, nodesString(d.NodesA, d.StepA), nodesString(d.NodesB, d.StepB))
+	if len(d.NodesA) > 0 && len(d.NodesB) > 0 {
+		s += fmt.Sprintf("  rtt %v -> %v (%+.3fms)", d.RTTA, d.RTTB, float64(d.RTTDelta.Microseconds())/1000)
+	}
+	return s
+}
+
+func nodesString(nodes []net.IP, step int) string {
+	if step == 0 {
+		return "-"
+	}
+	if len(nodes) == 0 {
+		return "*"
+	}
+	s := make([]string, len(nodes))
+	for i, n := range nodes {
+		s[i] = n.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// TraceDiff is a result of comparing two traces of the same target
+type TraceDiff struct {
+	Hops []HopDiff
+}
+
+// Changed returns true if the path has changed, e.g. there are added, removed or changed hops
+func (d TraceDiff) Changed() bool {
+	for _, h := range d.Hops {
+		if h.Kind == HopChanged || h.Kind == HopAdded || h.Kind == HopRemoved {
+			return true
+		}
+	}
+	return false
+}
+
+func (d TraceDiff) String() string {
+	lines := make([]string, len(d.Hops))
+	for i := range d.Hops {
+		lines[i] = d.Hops[i].String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffStep is the aggregated state of one trace step
+type diffStep struct {
+	step  int
+	nodes []net.IP
+	rtt   time.Duration
+}
+
+func (s diffStep) silent() bool {
+	return len(s.nodes) == 0
+}
+
+// overlap returns the number of responders both steps have in common
+func (s diffStep) overlap(o diffStep) (n int) {
+	for _, a := range s.nodes {
+		for _, b := range o.nodes {
+			if a.Equal(b) {
+				n++
+			}
+		}
+	}
+	return
+}
+
+// diffSteps groups hops by step, several hops with the same step and several probes of a hop
+// (e.g. load-balanced probes) are merged
+func diffSteps(hops []Hop) (steps []diffStep) {
+	idx := map[int]int{}
+	for _, h := range hops {
+		i, ok := idx[h.Step]
+		if !ok {
+			i = len(steps)
+			idx[h.Step] = i
+			steps = append(steps, diffStep{step: h.Step})
+		}
+		s := &steps[i]
+		for _, r := range h.replies() {
+			if s.silent() || r.Elapsed < s.rtt {
+				s.rtt = r.Elapsed
+			}
+			if s.overlap(diffStep{nodes: []net.IP{r.Node.IP}}) == 0 {
+				s.nodes = append(s.nodes, r.Node.IP)
+			}
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].step < steps[j].step })
+	return
+}
+
+// alignment costs used to find the best matching of the trace steps
+const (
+	diffCostMatch  = 0
+	diffCostSilent = 1
+	diffCostChange = 2
+	diffCostGap    = 2
+)
+
+func diffCost(a, b diffStep) int {
+	switch {
+	case a.silent() || b.silent():
+		return diffCostSilent
+	case a.overlap(b) > 0:
+		return diffCostMatch
+	default:
+		return diffCostChange
+	}
+}
+
+// Diff compares two traces of the same target and returns per hop differences.
+// Hops are aligned with the minimal edit distance, so an inserted or removed hop doesn't
+// mark all the following hops as changed. Silent hops match any hop but are reported as HopSilent,
+// several hops with the same step (load-balanced responders) are compared as a set.
+func Diff(a, b []Hop) (d TraceDiff) {
+	sa, sb := diffSteps(a), diffSteps(b)
+
+	// cost[i][j] is the minimal cost to align sa[i:] and sb[j:]
+	cost := make([][]int, len(sa)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(sb)+1)
+	}
+	for i := len(sa); i >= 0; i-- {
+		for j := len(sb); j >= 0; j-- {
+			switch {
+			case i == len(sa):
+				cost[i][j] = (len(sb) - j) * diffCostGap
+			case j == len(sb):
+				cost[i][j] = (len(sa) - i) * diffCostGap
+			default:
+				cost[i][j] = min(cost[i+1][j+1]+diffCost(sa[i], sb[j]), cost[i+1][j]+diffCostGap, cost[i][j+1]+diffCostGap)
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(sa) || j < len(sb) {
+		switch {
+		case i < len(sa) && j < len(sb) && cost[i][j] == cost[i+1][j+1]+diffCost(sa[i], sb[j]):
+			d.Hops = append(d.Hops, newHopDiff(sa[i], sb[j]))
+			i++
+			j++
+		case i < len(sa) && (j == len(sb) || cost[i][j] == cost[i+1][j]+diffCostGap):
+			d.Hops = append(d.Hops, HopDiff{Kind: HopRemoved, StepA: sa[i].step, NodesA: sa[i].nodes, RTTA: sa[i].rtt})
+			i++
+		default:
+			d.Hops = append(d.Hops, HopDiff{Kind: HopAdded, StepB: sb[j].step, NodesB: sb[j].nodes, RTTB: sb[j].rtt})
+			j++
+		}
+	}
+	return
+}
+
+func newHopDiff(a, b diffStep) HopDiff {
+	d := HopDiff{
+		StepA:  a.step,
+		StepB:  b.step,
+		NodesA: a.nodes,
+		NodesB: b.nodes,
+		RTTA:   a.rtt,
+		RTTB:   b.rtt,
+	}
+	switch {
+	case a.silent() || b.silent():
+		d.Kind = HopSilent
+		return d
+	case len(a.nodes) == len(b.nodes) && a.overlap(b) == len(a.nodes):
+		d.Kind = HopUnchanged
+	case a.overlap(b) > 0:
+		d.Kind = HopAlternate
+	default:
+		d.Kind = HopChanged
+	}
+	d.RTTDelta = b.rtt - a.rtt
+	return d
+}
@@ -0,0 +1,97 @@
+package gotraceroute
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTrace(nodes ...string) (hops []Hop) {
+	for i, n := range nodes {
+		for _, addr := range strings.Split(n, ",") {
+			h := Hop{Step: i + 1, Elapsed: time.Duration(i+1) * time.Millisecond}
+			if addr != "*" {
+				h.Success = true
+				h.Node.IP = net.ParseIP(addr)
+			}
+			hops = append(hops, h)
+		}
+	}
+	return
+}
+
+// testProbeTrace returns the trace with several probes sent to every hop, the comma separated addresses
+// are responders of the probes of the hop
+func testProbeTrace(nodes ...string) (hops []Hop) {
+	for i, n := range nodes {
+		h := Hop{Step: i + 1, Elapsed: time.Duration(i+1) * time.Millisecond}
+		for _, addr := range strings.Split(n, ",") {
+			p := HopProbe{Elapsed: h.Elapsed}
+			if addr != "*" {
+				p.Success = true
+				p.Node.IP = net.ParseIP(addr)
+				if !h.Success {
+					h.Success, h.Node = true, p.Node
+				}
+			}
+			h.Probes = append(h.Probes, p)
+		}
+		hops = append(hops, h)
+	}
+	return
+}
+
+func TestDiff(t *testing.T) {
+	a := testTrace("10.0.0.1", "192.0.2.1", "*", "192.0.2.3", "192.0.2.4,192.0.2.5", "8.8.8.8")
+	b := testTrace("10.0.0.1", "192.0.2.1", "192.0.2.2", "192.0.2.9", "192.0.2.33", "192.0.2.5", "8.8.8.8")
+
+	d := Diff(a, b)
+	var kinds []string
+	for _, h := range d.Hops {
+		kinds = append(kinds, string(h.Kind))
+	}
+	expected := "unchanged unchanged silent changed added alternate unchanged"
+	if strings.Join(kinds, " ") != expected {
+		t.Errorf("unexpected diff:\n%v", d.String())
+	}
+	if !d.Changed() {
+		t.Errorf("expected the path to be changed")
+	}
+	if last := d.Hops[len(d.Hops)-1]; last.StepA != 6 || last.StepB != 7 || last.RTTDelta != time.Millisecond {
+		t.Errorf("unexpected last hop diff: %v", last.String())
+	}
+
+	if Diff(a, a).Changed() {
+		t.Errorf("trace shouldn't differ from itself")
+	}
+}
+
+func TestDiffProbes(t *testing.T) {
+	// responders of all probes of a hop are compared, not the first one only
+	a := testProbeTrace("10.0.0.1", "192.0.2.4,*,192.0.2.5", "8.8.8.8")
+	b := testTrace("10.0.0.1", "192.0.2.5", "8.8.8.8")
+	var kinds []string
+	for _, h := range Diff(a, b).Hops {
+		kinds = append(kinds, string(h.Kind))
+	}
+	if strings.Join(kinds, " ") != "unchanged alternate unchanged" {
+		t.Errorf("unexpected diff:\n%v", Diff(a, b).String())
+	}
+	if PathFingerprint(a) != PathFingerprint(testTrace("10.0.0.1", "192.0.2.5,192.0.2.4", "8.8.8.8")) {
+		t.Errorf("fingerprint doesn't include responders of all probes")
+	}
+}
+
+func TestDecodeHops(t *testing.T) {
+	h := Hop{Success: true, Step: 1, Node: Addr{IP: net.ParseIP("192.0.2.1")}}
+	for _, s := range []string{"[" + h.StringJSON(false) + "," + h.StringJSON(true) + "]", h.StringJSON(false) + "\n" + h.StringJSON(false) + "\n"} {
+		hops, err := DecodeHops(strings.NewReader(s))
+		if err != nil {
+			t.Fatalf("DecodeHops failed: %v", err)
+		}
+		if len(hops) != 2 || !hops[1].Node.IP.Equal(h.Node.IP) {
+			t.Errorf("unexpected decoded hops: %v", hops)
+		}
+	}
+}
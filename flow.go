@@ -6,10 +6,49 @@ import (
 	"net"
 	"sync"
 	"syscall"
+	"time"
 )
 
-var nextFlowID uint16
-var nextFlowIDMutex sync.Mutex
+// maxFlows is the number of flow ids the BPF filter can tell apart (10 bits are assigned to the flow id),
+// it's the max number of concurrent flows in the process
+const maxFlows = 1<<10 - 1
+
+// flowIDs are the ids of open flows, every open flow has its own id,
+// the next id is searched from the last allocated one, so a freed id isn't reused at once
+var flowIDs = struct {
+	sync.Mutex
+	used map[uint16]bool
+	last uint16
+}{used: map[uint16]bool{}}
+
+// ErrTooManyFlows is returned by Run if the number of concurrent traceroutes of the process is at the limit
+var ErrTooManyFlows = fmt.Errorf("too many concurrent traceroutes, max is %v", maxFlows)
+
+// allocFlowID returns the id not used by other open flows
+func allocFlowID() (uint16, error) {
+	flowIDs.Lock()
+	defer flowIDs.Unlock()
+	if len(flowIDs.used) >= maxFlows {
+		return 0, ErrTooManyFlows
+	}
+	id := flowIDs.last
+	for flowIDs.used[id] {
+		id = (id + 1) % maxFlows
+	}
+	flowIDs.used[id] = true
+	flowIDs.last = (id + 1) % maxFlows
+	return id, nil
+}
+
+func freeFlowID(id uint16) {
+	flowIDs.Lock()
+	delete(flowIDs.used, id)
+	flowIDs.Unlock()
+}
+
+// recvBufferSize is enough to receive any reply the socket filter passes, the filter passes the first 256 bytes
+// of a reply, so ICMP extensions like MPLS labels may be cut off, the reply size is taken from its IP header anyway
+const recvBufferSize = 1500
 
 // flow describes one traceroute flow to the address destAddr,
 // should be created with newFlow and close() method should be call when
@@ -20,11 +59,76 @@ type flow struct {
 	sSocket    int
 	rSocket    int
 	flowID     uint16
+	// capture records sent probes and received packets if it isn't nil
+	capture *PcapWriter
+	// captureSrc is the source address of captured probes, the kernel fills it in for sent packets
+	captureSrc net.IP
 }
 
 func (f *flow) close() {
 	_ = syscall.Close(f.sSocket)
 	_ = syscall.Close(f.rSocket)
+	freeFlowID(f.flowID)
+}
+
+// send sends the raw ip packet to the address dst
+func (f *flow) send(pkt []byte, dst net.IP, port int) error {
+	addr := syscall.SockaddrInet4{Port: port}
+	copy(addr.Addr[:], dst.To4())
+	err := syscall.Sendto(f.sSocket, pkt, 0, &addr)
+	if err == nil && f.capture != nil {
+		_ = f.capture.WritePacket(time.Now(), f.capturedProbe(pkt, dst))
+	}
+	return err
+}
+
+// capturedProbe returns the copy of the probe packet with the source address and the header checksum
+// filled in the same way the kernel does it on sending
+func (f *flow) capturedProbe(pkt []byte, dst net.IP) []byte {
+	if f.captureSrc == nil {
+		f.captureSrc = f.socketAddr.To4()
+		if f.captureSrc == nil || f.captureSrc.IsUnspecified() {
+			// the connected udp socket reveals the source address the kernel chooses for the destination,
+			// no packets are sent
+			if c, err := net.Dial("udp4", net.JoinHostPort(dst.String(), "9")); err == nil {
+				f.captureSrc = c.LocalAddr().(*net.UDPAddr).IP.To4()
+				_ = c.Close()
+			}
+		}
+	}
+	p := append([]byte(nil), pkt...)
+	if len(p) < 20 || f.captureSrc == nil {
+		return p
+	}
+	copy(p[12:16], f.captureSrc)
+	p[10], p[11] = 0, 0
+	var sum uint32
+	for i := 0; i < 20; i += 2 {
+		sum += uint32(p[i])<<8 | uint32(p[i+1])
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	p[10], p[11] = ^byte(sum>>8), ^byte(sum)
+	return p
+}
+
+// setRecvTimeout sets the timeout to wait for a packet on the receiving socket
+func (f *flow) setRecvTimeout(timeout time.Duration) error {
+	// solution with using SetsockoptTimeval at every Recvfrom isn't optimal,
+	// it's better to use poll (epoll)
+	tv := syscall.NsecToTimeval(timeout.Nanoseconds())
+	return syscall.SetsockoptTimeval(f.rSocket, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv)
+}
+
+// recv receives the next ICMP packet filtered by the flow BPF filter to the buffer,
+// syscall.EWOULDBLOCK is returned on timeout
+func (f *flow) recv(buf []byte) (n int, err error) {
+	n, _, err = syscall.Recvfrom(f.rSocket, buf, 0)
+	if err == nil && f.capture != nil {
+		_ = f.capture.WritePacket(time.Now(), buf[:n])
+	}
+	return
 }
 
 // newFlow initializes sockets and returns flow struct
@@ -37,6 +141,16 @@ func newFlow(destAddr net.IP, srcPort int, networkInterface string) (f flow, err
 		return
 	}
 
+	// assign flowId to identify only this flow packets on a raw socket
+	if f.flowID, err = allocFlowID(); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			freeFlowID(f.flowID)
+		}
+	}()
+
 	addr := syscall.SockaddrInet4{
 		Port: srcPort,
 	}
@@ -88,12 +202,6 @@ func newFlow(destAddr net.IP, srcPort int, networkInterface string) (f flow, err
 		}
 	*/
 
-	// assign flowId to identify only this flow packets on a raw socket
-	nextFlowIDMutex.Lock()
-	f.flowID = nextFlowID
-	nextFlowID = (nextFlowID + 1) % (1<<10 - 1) // max 10 bits assign to flowId
-	nextFlowIDMutex.Unlock()
-
 	// apply BPF filter to the socket
 	err = bpfFlowID(f.flowID).applyToSocket(f.rSocket)
 
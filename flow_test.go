@@ -0,0 +1,36 @@
+package gotraceroute
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllocFlowID(t *testing.T) {
+	var ids []uint16
+	defer func() {
+		for _, id := range ids {
+			freeFlowID(id)
+		}
+	}()
+	seen := map[uint16]bool{}
+	for {
+		id, err := allocFlowID()
+		if errors.Is(err, ErrTooManyFlows) {
+			break
+		}
+		if seen[id] || id >= maxFlows {
+			t.Fatalf("flow id %v is allocated twice or out of range", id)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) != maxFlows {
+		t.Fatalf("allocated %v flow ids, max is %v", len(ids), maxFlows)
+	}
+
+	// the freed id is the only one available
+	freeFlowID(ids[10])
+	if id, err := allocFlowID(); err != nil || id != ids[10] {
+		t.Errorf("expected the freed flow id %v, got %v (%v)", ids[10], id, err)
+	}
+}
@@ -0,0 +1,117 @@
+package gotraceroute
+
+import (
+	"fmt"
+	"golang.org/x/net/ipv4"
+	"io"
+	"strings"
+)
+
+// HopEncoder writes hops of a traceroute in an output format as they arrive
+type HopEncoder interface {
+	// Encode writes the hop
+	Encode(h Hop) error
+	// Close completes the output, it has to be called when the traceroute is finished or has failed,
+	// err is the traceroute error if any
+	Close(err error) error
+}
+
+// TracerouteEncoder writes hops in the format of the Linux traceroute utility:
+//
+//	traceroute to example.com (93.184.216.34), 32 hops max, 28 byte packets
+//	 1  gateway (192.168.1.1)  0.512 ms  0.431 ms  0.402 ms
+//	 2  * * *
+//	 3  10.0.0.1 (10.0.0.1)  5.123 ms 10.0.0.2 (10.0.0.2)  5.532 ms  5.610 ms !H
+//
+// Every probe of the hop is printed on the hop line, the address is printed only when it differs from the previous probe.
+// Late updates of already written hops are skipped, a line can't be changed once it's written
+type TracerouteEncoder struct {
+	w       io.Writer
+	target  string
+	options Options
+	header  bool
+	last    int
+}
+
+// NewTracerouteEncoder returns the encoder of the traceroute to target executed with options
+func NewTracerouteEncoder(w io.Writer, target string, options Options) *TracerouteEncoder {
+	return &TracerouteEncoder{w: w, target: target, options: options}
+}
+
+// Encode writes the hop line, the header is written before the first hop
+func (e *TracerouteEncoder) Encode(h Hop) error {
+	if e.header && h.Step <= e.last {
+		return nil
+	}
+	var b strings.Builder
+	if !e.header {
+		fmt.Fprintf(&b, "traceroute to %s (%s), %d hops max, %d byte packets\n",
+			e.target, h.Dst.IP, e.options.maxHops(), ipv4.HeaderLen+8+e.options.payloadSize())
+		e.header = true
+	}
+	e.last = h.Step
+
+	fmt.Fprintf(&b, "%2d ", h.Step)
+	probes := h.Probes
+	if len(probes) == 0 {
+		probes = []HopProbe{h.probe()}
+	}
+	var prev *Addr
+	for i := range probes {
+		p := probes[i]
+		if !p.Success {
+			b.WriteString(" *")
+			continue
+		}
+		if prev == nil || !prev.IP.Equal(p.Node.IP) {
+			if e.options.DontResolve {
+				fmt.Fprintf(&b, " %s", p.Node.IP)
+			} else {
+				fmt.Fprintf(&b, " %s (%s)", p.Node.HostOrAddr(), p.Node.IP)
+			}
+			prev = &probes[i].Node
+		}
+		fmt.Fprintf(&b, "  %.3f ms", float64(p.Elapsed.Microseconds())/1000)
+		if a := icmpAnnotation(p.IcmpType, p.IcmpCode); a != "" {
+			b.WriteString(" " + a)
+		}
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// Close does nothing, the traceroute error isn't a part of the output
+func (e *TracerouteEncoder) Close(_ error) error {
+	return nil
+}
+
+// icmpAnnotation returns the traceroute annotation of the ICMP destination unreachable code,
+// e.g. !H for the host unreachable, the port unreachable means the destination is reached and isn't annotated
+func icmpAnnotation(icmpType, code int) string {
+	if icmpType != int(ipv4.ICMPTypeDestinationUnreachable) {
+		return ""
+	}
+	switch code {
+	case 0, 6, 8, 11:
+		return "!N"
+	case 1, 7, 12:
+		return "!H"
+	case 2:
+		return "!P"
+	case 3:
+		return ""
+	case 4:
+		return "!F"
+	case 5:
+		return "!S"
+	case 9, 10, 13:
+		return "!X"
+	case 14:
+		return "!V"
+	case 15:
+		return "!C"
+	default:
+		return fmt.Sprintf("!<%d>", code)
+	}
+}
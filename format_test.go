@@ -0,0 +1,64 @@
+package gotraceroute
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTracerouteEncoder(t *testing.T) {
+	ms := time.Millisecond
+	node := func(host, ip string) Addr { return Addr{Host: host, IP: net.ParseIP(ip)} }
+	dst := Addr{IP: net.ParseIP("192.0.2.1")}
+	hops := []Hop{
+		{Step: 1, Success: true, Dst: dst, Node: node("gw", "10.0.0.1"), Probes: []HopProbe{
+			{Success: true, Node: node("gw", "10.0.0.1"), Elapsed: 512 * time.Microsecond},
+			{Success: true, Node: node("gw", "10.0.0.1"), Elapsed: 431 * time.Microsecond},
+			{Success: true, Node: node("gw", "10.0.0.1"), Elapsed: 402 * time.Microsecond},
+		}},
+		{Step: 2, Dst: dst, Probes: []HopProbe{{}, {}, {}}},
+		{Step: 3, Success: true, Dst: dst, Probes: []HopProbe{
+			{Success: true, Node: node("", "10.0.0.2"), Elapsed: 5 * ms},
+			{},
+			{Success: true, Node: node("", "10.0.0.3"), Elapsed: 6 * ms, IcmpType: 3, IcmpCode: 1},
+		}},
+		// the late update of the already written hop is skipped
+		{Step: 2, Success: true, Dst: dst, Node: node("", "10.0.0.9"), Elapsed: 300 * ms, Late: true},
+		{Step: 4, Success: true, Dst: dst, Node: node("", "192.0.2.1"), Elapsed: 7 * ms, IcmpType: 3, IcmpCode: 3},
+	}
+
+	var b strings.Builder
+	e := NewTracerouteEncoder(&b, "example.com", Options{MaxHops: 30, PayloadSize: 32})
+	for _, h := range hops {
+		if err := e.Encode(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = e.Close(nil)
+
+	expected := `traceroute to example.com (192.0.2.1), 30 hops max, 60 byte packets
+ 1  gw (10.0.0.1)  0.512 ms  0.431 ms  0.402 ms
+ 2  * * *
+ 3  10.0.0.2 (10.0.0.2)  5.000 ms * 10.0.0.3 (10.0.0.3)  6.000 ms !H
+ 4  192.0.2.1 (192.0.2.1)  7.000 ms
+`
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestRunProbesPerHop(t *testing.T) {
+	hops, err := RunBlock("127.0.0.1", Options{ProbesPerHop: 3, DontResolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hops) != 1 || len(hops[0].Probes) != 3 || !hops[0].Success {
+		t.Fatalf("unexpected hops %+v", hops)
+	}
+	for _, p := range hops[0].Probes {
+		if !p.Success || !p.Node.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+			t.Errorf("unexpected probe %+v", p)
+		}
+	}
+}
@@ -13,6 +13,8 @@ type Addr struct {
 	Host string
 	// IP is the IP address of the node.
 	IP net.IP
+	// Class is the class of the address space IP belongs to, it's filled in for the hop Node only.
+	Class AddrClass `json:",omitempty"`
 }
 
 func (a *Addr) String() string {
@@ -46,6 +48,9 @@ type Hop struct {
 	ID int
 	// DstPort is the destination port targeted.
 	DstPort int
+	// Attempt is the number of the answered probe of the hop counted from 0, e.g. 1 if the hop answered the first
+	// retry. It's 0 if several probes are sent to every hop, the answered probes are in Probes then.
+	Attempt int `json:",omitempty"`
 	// Sent is the time the query began.
 	Sent time.Time
 	// Received is the time the query completed.
@@ -54,6 +59,89 @@ type Hop struct {
 	Elapsed time.Duration
 	// IcmpType is the received ICMP packet type value.
 	IcmpType int
+	// IcmpCode is the received ICMP packet code value.
+	IcmpCode int
+	// ReplyTTL is the TTL of the received ICMP packet.
+	ReplyTTL int
+	// ReplyIPID is the IP identification field of the received ICMP packet.
+	ReplyIPID int
+	// ReplySize is the size of the received ICMP message without the IP header.
+	ReplySize int `json:",omitempty"`
+	// Late is true if the reply was received after the probe had been given up on and the next probe had been sent,
+	// Elapsed is the real round trip time of the answered probe anyway. A hop delivered again as an update is Late.
+	Late bool `json:",omitempty"`
+	// IXP is the internet exchange the node address belongs to, nil if the node isn't on a known IXP peering LAN.
+	IXP *IXP `json:",omitempty"`
+	// Probes are results of every probe sent to the hop if several probes are sent to every hop (see Options.ProbesPerHop),
+	// the hop fields are filled in from the first answered probe.
+	Probes []HopProbe `json:",omitempty"`
+}
+
+// HopProbe is a result of one of probes sent to the hop
+type HopProbe struct {
+	Success   bool
+	Node      Addr
+	Elapsed   time.Duration
+	IcmpType  int
+	IcmpCode  int
+	ReplyTTL  int  `json:",omitempty"`
+	ReplySize int  `json:",omitempty"`
+	Late      bool `json:",omitempty"`
+}
+
+// probe returns the probe result of the answered hop
+func (h *Hop) probe() HopProbe {
+	return HopProbe{Success: h.Success, Node: h.Node, Elapsed: h.Elapsed, IcmpType: h.IcmpType, IcmpCode: h.IcmpCode,
+		ReplyTTL: h.ReplyTTL, ReplySize: h.ReplySize, Late: h.Late}
+}
+
+// replies returns the answered probes of the hop as hops: every successful probe if several probes are sent
+// to the hop, otherwise the hop itself if it's answered, so load-balanced responders of the hop are all returned.
+// The IXP is kept for replies from the hop node only
+func (h *Hop) replies() (replies []Hop) {
+	if len(h.Probes) == 0 {
+		if h.Success && h.Node.IP != nil {
+			replies = append(replies, *h)
+		}
+		return
+	}
+	for _, p := range h.Probes {
+		if !p.Success || p.Node.IP == nil {
+			continue
+		}
+		r := *h
+		r.Probes = nil
+		r.Success, r.Node, r.Elapsed, r.IcmpType, r.IcmpCode = p.Success, p.Node, p.Elapsed, p.IcmpType, p.IcmpCode
+		r.ReplyTTL, r.ReplySize, r.Late = p.ReplyTTL, p.ReplySize, p.Late
+		if !p.Node.IP.Equal(h.Node.IP) {
+			r.IXP = nil
+		}
+		replies = append(replies, r)
+	}
+	return
+}
+
+// updateLate updates the hop with the late reply to its probe number n and returns true if the hop has changed,
+// the updated hop is Late even if it was answered before, so it's merged into the first delivery of the hop
+func (h *Hop) updateLate(reply Hop, n int) bool {
+	if len(h.Probes) == 0 {
+		if h.Success {
+			return false
+		}
+		*h = reply
+		return true
+	}
+	if n >= len(h.Probes) || h.Probes[n].Success {
+		return false
+	}
+	probes := append([]HopProbe(nil), h.Probes...)
+	probes[n] = reply.probe()
+	if !h.Success {
+		*h = reply
+	}
+	h.Probes = probes
+	h.Late = true
+	return true
 }
 
 func (h *Hop) String() string {
@@ -75,23 +163,85 @@ func (h *Hop) StringHuman() string {
 	if !h.Success {
 		return fmt.Sprintf("%-3d *", h.Step)
 	}
-	return fmt.Sprintf("%-3d %v (%v)  %vms", h.Step, h.Node.HostOrAddr(), h.Node.IP.String(), h.Elapsed.Milliseconds())
+	s := fmt.Sprintf("%-3d %v (%v)  %vms", h.Step, h.Node.HostOrAddr(), h.Node.IP.String(), h.Elapsed.Milliseconds())
+	if h.Late {
+		s += "  [late]"
+	}
+	if h.Node.Class != "" && !h.Node.Class.IsPublic() {
+		s += fmt.Sprintf("  [%v]", h.Node.Class)
+	}
+	if h.IXP != nil {
+		s += fmt.Sprintf("  [%v]", h.IXP.String())
+	}
+	return s
+}
+
+// fieldIP returns the address field value, it's empty for unknown addresses of unanswered hops
+func fieldIP(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
 }
+
+// fieldTime returns the time field value, it's empty for the zero time
+func fieldTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
 func (h *Hop) Fields() map[string]interface{} {
-	return map[string]interface{}{
-		"success":  h.Success,
-		"srchost":  h.Src.Host,
-		"srcip":    h.Src.IP.String(),
-		"dsthost":  h.Dst.Host,
-		"dstip":    h.Dst.IP.String(),
-		"nodehost": h.Node.Host,
-		"nodeip":   h.Node.IP.String(),
-		"step":     h.Step,
-		"id":       h.ID,
-		"sent":     h.Sent.Format(time.RFC3339Nano),
-		"received": h.Received.Format(time.RFC3339Nano),
-		"elapsed":  h.Elapsed.Milliseconds(),
+	f := map[string]interface{}{
+		"success":   h.Success,
+		"srchost":   h.Src.Host,
+		"srcip":     fieldIP(h.Src.IP),
+		"dsthost":   h.Dst.Host,
+		"dstip":     fieldIP(h.Dst.IP),
+		"nodehost":  h.Node.Host,
+		"nodeip":    fieldIP(h.Node.IP),
+		"nodeclass": string(h.Node.Class),
+		"step":      h.Step,
+		"id":        h.ID,
+		"sent":      fieldTime(h.Sent),
+		"received":  fieldTime(h.Received),
+		"elapsed":   h.Elapsed.Milliseconds(),
+		"late":      h.Late,
+	}
+	if h.IXP != nil {
+		f["ixp"] = h.IXP.Name
+		f["ixpmemberasn"] = h.IXP.MemberASN
+		f["ixpmember"] = h.IXP.MemberName
+	}
+	for i, p := range h.Probes {
+		n := fmt.Sprintf("probe%d", i+1)
+		f[n+"success"] = p.Success
+		f[n+"nodeip"] = fieldIP(p.Node.IP)
+		f[n+"elapsed"] = p.Elapsed.Milliseconds()
+	}
+	return f
+}
+
+// UpdateHops adds the hop received from Run to the hops: the hop is appended or, if it's the late reply update
+// of a hop already in the list, it replaces the hop of the same step
+func UpdateHops(hops []Hop, h Hop) []Hop {
+	for i := len(hops) - 1; i >= 0; i-- {
+		if hops[i].Step == h.Step {
+			hops[i] = h
+			return hops
+		}
+	}
+	return append(hops, h)
+}
+
+// Reached returns true if the last hop of the trace is the destination
+func Reached(hops []Hop) bool {
+	if len(hops) == 0 {
+		return false
 	}
+	last := hops[len(hops)-1]
+	return last.Success && last.Node.IP.Equal(last.Dst.IP)
 }
 
 func newHop(flowID int, src net.IP, dst net.IP, ttl int) Hop {
@@ -0,0 +1,75 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influx line protocol measurements
+const (
+	influxHopMeasurement   = "traceroute_hop"
+	influxTraceMeasurement = "traceroute"
+)
+
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+var influxStringEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+
+// InfluxSink writes data points in the InfluxDB line protocol, the output can be loaded with influx write,
+// Telegraf or posted to the /api/v2/write endpoint:
+//
+//	traceroute_hop,target=example.com,hop=3,responder=192.0.2.1 success=true,rtt_ms=12.345,reply_ttl=253i,late=false 1700000000000000000
+//	traceroute,target=example.com,dst=93.184.216.34 hops=12i,reached=true,duration_ms=1523.2 1700000000000000000
+type InfluxSink struct {
+	w *bufio.Writer
+}
+
+// NewInfluxSink returns the sink writing line protocol to w
+func NewInfluxSink(w io.Writer) *InfluxSink {
+	return &InfluxSink{w: bufio.NewWriter(w)}
+}
+
+func (s *InfluxSink) writeLine(measurement string, tags [][2]string, fields []string, t time.Time) error {
+	var b strings.Builder
+	b.WriteString(measurement)
+	for _, tag := range tags {
+		if tag[1] != "" {
+			b.WriteString("," + influxTagEscaper.Replace(tag[0]) + "=" + influxTagEscaper.Replace(tag[1]))
+		}
+	}
+	b.WriteString(" " + strings.Join(fields, ",") + " " + strconv.FormatInt(t.UnixNano(), 10) + "\n")
+	_, err := s.w.WriteString(b.String())
+	return err
+}
+
+// WriteHop writes the traceroute_hop line, the RTT and the reply TTL are written for answered hops only
+func (s *InfluxSink) WriteHop(target string, h Hop, asn int) error {
+	fields := []string{"success=" + strconv.FormatBool(h.Success)}
+	if h.Success {
+		fields = append(fields, "rtt_ms="+strconv.FormatFloat(float64(h.Elapsed.Microseconds())/1000, 'f', -1, 64),
+			"reply_ttl="+strconv.Itoa(h.ReplyTTL)+"i")
+	}
+	fields = append(fields, "late="+strconv.FormatBool(h.Late))
+	return s.writeLine(influxHopMeasurement, metricTags(target, h, asn), fields, hopTime(h))
+}
+
+// WriteSummary writes the traceroute line
+func (s *InfluxSink) WriteSummary(t TraceSummary) error {
+	tags := [][2]string{{"target", t.Target}}
+	if t.Dst != nil {
+		tags = append(tags, [2]string{"dst", t.Dst.String()})
+	}
+	fields := []string{"hops=" + strconv.Itoa(t.Hops) + "i", "reached=" + strconv.FormatBool(t.Reached),
+		"duration_ms=" + strconv.FormatFloat(float64(t.Duration.Microseconds())/1000, 'f', -1, 64)}
+	if t.Error != "" {
+		fields = append(fields, `error="`+influxStringEscaper.Replace(t.Error)+`"`)
+	}
+	return s.writeLine(influxTraceMeasurement, tags, fields, t.Started)
+}
+
+// Flush writes the buffered lines
+func (s *InfluxSink) Flush() error {
+	return s.w.Flush()
+}
@@ -0,0 +1,228 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// IXP describes an internet exchange point peering LAN the hop address belongs to
+type IXP struct {
+	// Name is the name of the exchange
+	Name string
+	// Prefix is the peering LAN prefix the address belongs to
+	Prefix string
+	// MemberASN is the AS number of the member network the address is assigned to, 0 if unknown
+	MemberASN int `json:",omitempty"`
+	// MemberName is the name of the member network the address is assigned to, if known
+	MemberName string `json:",omitempty"`
+}
+
+func (x *IXP) String() string {
+	s := "IX " + x.Name
+	switch {
+	case x.MemberASN != 0 && x.MemberName != "":
+		s += fmt.Sprintf(", member AS%d %s", x.MemberASN, x.MemberName)
+	case x.MemberASN != 0:
+		s += fmt.Sprintf(", member AS%d", x.MemberASN)
+	case x.MemberName != "":
+		s += ", member " + x.MemberName
+	}
+	return s
+}
+
+type ixpPrefix struct {
+	net  *net.IPNet
+	name string
+}
+
+type ixpMember struct {
+	asn  int
+	name string
+}
+
+// IXPDB is a set of IXP peering LAN prefixes used to mark hops crossing public peering.
+// It should be created with LoadIXPDB or ParseIXPDB
+type IXPDB struct {
+	prefixes []ixpPrefix
+	members  map[string]ixpMember
+}
+
+// LoadIXPDB loads IXP peering LAN prefixes from the file, see ParseIXPDB for supported formats
+func LoadIXPDB(path string) (db *IXPDB, err error) {
+	f, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	db, err = ParseIXPDB(f)
+	if err != nil {
+		err = fmt.Errorf("can't load ixp database %v: %w", path, err)
+	}
+	return
+}
+
+// ParseIXPDB reads IXP peering LAN prefixes. Two formats are supported:
+//   - PeeringDB JSON export (the ix, ixlan, ixpfx, netixlan and net objects are used),
+//     in this case the member network is derived from netixlan records;
+//   - a plain text file where every line contains a prefix and an exchange name
+//     separated by whitespaces, empty lines and lines started with # are ignored.
+func ParseIXPDB(r io.Reader) (db *IXPDB, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	db = &IXPDB{members: map[string]ixpMember{}}
+	if d := bytes.TrimSpace(data); len(d) > 0 && d[0] == '{' {
+		err = db.parsePeeringDB(d)
+	} else {
+		err = db.parseText(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the longest prefix should be matched first
+	sort.SliceStable(db.prefixes, func(i, j int) bool {
+		li, _ := db.prefixes[i].net.Mask.Size()
+		lj, _ := db.prefixes[j].net.Mask.Size()
+		return li > lj
+	})
+	return
+}
+
+func (db *IXPDB) addPrefix(prefix string, name string) error {
+	_, n, err := net.ParseCIDR(strings.TrimSpace(prefix))
+	if err != nil {
+		return err
+	}
+	db.prefixes = append(db.prefixes, ixpPrefix{net: n, name: name})
+	return nil
+}
+
+func (db *IXPDB) parseText(data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) < 2 {
+			return fmt.Errorf("line %d: expected prefix and exchange name", line)
+		}
+		if err := db.addPrefix(fields[0], strings.Join(fields[1:], " ")); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// peeringDBDump is the subset of PeeringDB JSON export objects used to build IXPDB
+type peeringDBDump struct {
+	IX struct {
+		Data []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	} `json:"ix"`
+	IXLan struct {
+		Data []struct {
+			ID   int `json:"id"`
+			IXID int `json:"ix_id"`
+		} `json:"data"`
+	} `json:"ixlan"`
+	IXPfx struct {
+		Data []struct {
+			IXLanID int    `json:"ixlan_id"`
+			Prefix  string `json:"prefix"`
+		} `json:"data"`
+	} `json:"ixpfx"`
+	NetIXLan struct {
+		Data []struct {
+			NetID   int    `json:"net_id"`
+			ASN     int    `json:"asn"`
+			Name    string `json:"name"`
+			IPAddr4 string `json:"ipaddr4"`
+			IPAddr6 string `json:"ipaddr6"`
+		} `json:"data"`
+	} `json:"netixlan"`
+	Net struct {
+		Data []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	} `json:"net"`
+}
+
+func (db *IXPDB) parsePeeringDB(data []byte) (err error) {
+	var dump peeringDBDump
+	if err = json.Unmarshal(data, &dump); err != nil {
+		return
+	}
+
+	ixNames := make(map[int]string, len(dump.IX.Data))
+	for _, ix := range dump.IX.Data {
+		ixNames[ix.ID] = ix.Name
+	}
+	lanIX := make(map[int]int, len(dump.IXLan.Data))
+	for _, lan := range dump.IXLan.Data {
+		lanIX[lan.ID] = lan.IXID
+	}
+	for _, p := range dump.IXPfx.Data {
+		name, ok := ixNames[lanIX[p.IXLanID]]
+		if !ok {
+			name = fmt.Sprintf("ixlan %d", p.IXLanID)
+		}
+		if err = db.addPrefix(p.Prefix, name); err != nil {
+			return
+		}
+	}
+
+	netNames := make(map[int]string, len(dump.Net.Data))
+	for _, n := range dump.Net.Data {
+		netNames[n.ID] = n.Name
+	}
+	for _, m := range dump.NetIXLan.Data {
+		member := ixpMember{asn: m.ASN, name: netNames[m.NetID]}
+		for _, a := range []string{m.IPAddr4, m.IPAddr6} {
+			if ip := net.ParseIP(a); ip != nil {
+				db.members[ip.String()] = member
+			}
+		}
+	}
+	return
+}
+
+// Len returns the number of loaded peering LAN prefixes
+func (db *IXPDB) Len() int {
+	return len(db.prefixes)
+}
+
+// Lookup returns the IXP the ip address belongs to or nil if the address isn't on any known peering LAN
+func (db *IXPDB) Lookup(ip net.IP) *IXP {
+	if db == nil || ip == nil {
+		return nil
+	}
+	for _, p := range db.prefixes {
+		if !p.net.Contains(ip) {
+			continue
+		}
+		ixp := &IXP{Name: p.name, Prefix: p.net.String()}
+		if m, ok := db.members[ip.String()]; ok {
+			ixp.MemberASN = m.asn
+			ixp.MemberName = m.name
+		}
+		return ixp
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package gotraceroute
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+const testPeeringDB = `{
+	"ix": {"data": [{"id": 1, "name": "DE-CIX Frankfurt"}]},
+	"ixlan": {"data": [{"id": 10, "ix_id": 1}]},
+	"ixpfx": {"data": [{"ixlan_id": 10, "prefix": "80.81.192.0/21", "protocol": "IPv4"}]},
+	"netixlan": {"data": [{"net_id": 100, "asn": 15169, "name": "Google LLC", "ipaddr4": "80.81.192.108", "ipaddr6": null}]},
+	"net": {"data": [{"id": 100, "name": "Google LLC", "asn": 15169}]}
+}`
+
+func TestIXPDBPeeringDB(t *testing.T) {
+	db, err := ParseIXPDB(strings.NewReader(testPeeringDB))
+	if err != nil {
+		t.Fatalf("ParseIXPDB failed: %v", err)
+	}
+	ixp := db.Lookup(net.ParseIP("80.81.192.108"))
+	if ixp == nil || ixp.Name != "DE-CIX Frankfurt" || ixp.MemberASN != 15169 || ixp.MemberName != "Google LLC" {
+		t.Errorf("unexpected lookup result: %+v", ixp)
+	}
+	ixp = db.Lookup(net.ParseIP("80.81.193.1"))
+	if ixp == nil || ixp.MemberASN != 0 {
+		t.Errorf("unexpected lookup result for unknown member: %+v", ixp)
+	}
+	if db.Lookup(net.ParseIP("8.8.8.8")) != nil {
+		t.Errorf("8.8.8.8 shouldn't be on IXP peering LAN")
+	}
+}
+
+func TestIXPDBText(t *testing.T) {
+	db, err := ParseIXPDB(strings.NewReader("# comment\n195.66.224.0/22 LINX LON1\n195.66.224.0/21 wide\n"))
+	if err != nil {
+		t.Fatalf("ParseIXPDB failed: %v", err)
+	}
+	if db.Len() != 2 {
+		t.Errorf("expected 2 prefixes, got %d", db.Len())
+	}
+	if ixp := db.Lookup(net.ParseIP("195.66.225.1")); ixp == nil || ixp.Name != "LINX LON1" {
+		t.Errorf("expected the longest prefix match, got %+v", ixp)
+	}
+	if _, err = ParseIXPDB(strings.NewReader("bad\n")); err == nil {
+		t.Errorf("expected error on malformed line")
+	}
+}
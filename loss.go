@@ -0,0 +1,251 @@
+package gotraceroute
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const DefaultBurstSize = 20
+
+// maxBurstSize is the number of probe ids available to a flow
+const maxBurstSize = 1<<6 - 2
+
+// LossKind is the probable cause of the loss at a hop
+type LossKind string
+
+const (
+	// LossNone means there is no loss at the hop
+	LossNone LossKind = ""
+	// LossForwardedPath means the loss carries on to the later hops, e.g. packets forwarded through the hop are lost
+	LossForwardedPath LossKind = "forwarded-path loss"
+	// LossRateLimit means the loss doesn't carry on to the later hops or the hop answers bursts in a rate limiter pattern,
+	// e.g. the router just limits ICMP generation by its control plane while forwarding works fine
+	LossRateLimit LossKind = "likely control-plane rate limit"
+)
+
+// HopStats is the statistics of a hop over repeated traces to the same destination
+type HopStats struct {
+	Step int
+	// Nodes are the addresses responded at the hop in order of the first appearance
+	Nodes []net.IP
+	// Hosts are the host names of Nodes, a name is empty if the address isn't resolved
+	Hosts    []string `json:",omitempty"`
+	Sent     int
+	Received int
+	// Loss is the ratio of unanswered probes
+	Loss     float64
+	LossKind LossKind `json:",omitempty"`
+	// Last is the RTT of the latest answered probe
+	Last  time.Duration
+	RTT   RTTStats
+	Burst *BurstResult `json:",omitempty"`
+}
+
+// String returns the human-readable representation of the hop statistics
+func (s HopStats) String() string {
+	nodes := make([]string, 0, len(s.Nodes))
+	for _, n := range s.Nodes {
+		nodes = append(nodes, n.String())
+	}
+	if len(nodes) == 0 {
+		nodes = append(nodes, "*")
+	}
+	str := fmt.Sprintf("%-3d %-32s  sent %d, loss %.1f%%", s.Step, strings.Join(nodes, ", "), s.Sent, s.Loss*100)
+	if s.RTT.Count > 0 {
+		str += fmt.Sprintf(", rtt min/avg/max/stddev %v/%v/%v/%v", s.RTT.Min.Round(time.Microsecond*10),
+			s.RTT.Avg.Round(time.Microsecond*10), s.RTT.Max.Round(time.Microsecond*10), s.RTT.StdDev.Round(time.Microsecond*10))
+	}
+	if s.LossKind != LossNone {
+		str += fmt.Sprintf("  [%s]", s.LossKind)
+	}
+	return str
+}
+
+// PathStats aggregates per-hop statistics of repeated traces to the same destination
+// and tells apart the real forwarded-path loss from the ICMP rate limiting of routers
+type PathStats struct {
+	steps  map[int]*HopStats
+	traces int
+}
+
+// NewPathStats returns the empty statistics
+func NewPathStats() *PathStats {
+	return &PathStats{steps: map[int]*HopStats{}}
+}
+
+// Traces returns the number of traces added
+func (p *PathStats) Traces() int {
+	return p.traces
+}
+
+func (p *PathStats) step(n int) *HopStats {
+	s, ok := p.steps[n]
+	if !ok {
+		s = &HopStats{Step: n}
+		p.steps[n] = s
+	}
+	return s
+}
+
+// Add adds the trace hops to the statistics, every probe of hops probed several times is counted
+func (p *PathStats) Add(hops []Hop) {
+	p.traces++
+	for _, h := range hops {
+		s := p.step(h.Step)
+		probes := h.Probes
+		if len(probes) == 0 {
+			probes = []HopProbe{h.probe()}
+		}
+		for _, r := range probes {
+			s.Sent++
+			if !r.Success {
+				continue
+			}
+			s.Received++
+			s.Last = r.Elapsed
+			s.RTT.add(r.Elapsed)
+			known := false
+			for _, n := range s.Nodes {
+				known = known || n.Equal(r.Node.IP)
+			}
+			if !known {
+				s.Nodes = append(s.Nodes, r.Node.IP)
+				s.Hosts = append(s.Hosts, r.Node.Host)
+			}
+		}
+	}
+}
+
+// AddBurst adds the result of the burst probing of a hop, see BurstProbe
+func (p *PathStats) AddBurst(b BurstResult) {
+	p.step(b.Step).Burst = &b
+}
+
+// Hops returns the statistics of every hop ordered by step with the loss classified.
+// The loss at a hop is a forwarded-path loss if at least a half of it carries on to every later responsive hop,
+// otherwise the hop only drops its own ICMP replies. A rate limiter pattern of the burst probing takes precedence.
+func (p *PathStats) Hops() []HopStats {
+	hops := make([]HopStats, 0, len(p.steps))
+	for _, s := range p.steps {
+		h := *s
+		if h.Sent > 0 {
+			h.Loss = float64(h.Sent-h.Received) / float64(h.Sent)
+		}
+		hops = append(hops, h)
+	}
+	sort.Slice(hops, func(i, j int) bool { return hops[i].Step < hops[j].Step })
+
+	for i := range hops {
+		if hops[i].Loss == 0 {
+			continue
+		}
+		if hops[i].Burst != nil && hops[i].Burst.RateLimited() {
+			hops[i].LossKind = LossRateLimit
+			continue
+		}
+		hops[i].LossKind = LossForwardedPath
+		for _, later := range hops[i+1:] {
+			// hops never responded don't tell anything about forwarding
+			if later.Received > 0 && later.Loss < hops[i].Loss/2 {
+				hops[i].LossKind = LossRateLimit
+				break
+			}
+		}
+	}
+	return hops
+}
+
+// BurstResult is the result of probing a hop with a burst of back-to-back probes
+type BurstResult struct {
+	Step int
+	// Node is the address responded to the burst
+	Node net.IP `json:",omitempty"`
+	// Answered marks answered probes in order of sending
+	Answered []bool
+}
+
+// RateLimited returns true if the burst is answered in a rate limiter pattern: the first probes are answered
+// till the limiter bucket is exhausted and all the rest are dropped, random loss doesn't look like this
+func (b BurstResult) RateLimited() bool {
+	answered := 0
+	for answered < len(b.Answered) && b.Answered[answered] {
+		answered++
+	}
+	if answered == 0 || len(b.Answered)-answered < 2 {
+		return false
+	}
+	for _, a := range b.Answered[answered:] {
+		if a {
+			return false
+		}
+	}
+	return true
+}
+
+// BurstProbe sends count probes with the ttl to the destination back-to-back and waits for replies.
+// The probe rate is still limited by options.RateLimiter, so a strict limiter may hide the rate limiter pattern
+func BurstProbe(ctx context.Context, dest string, ttl, count int, options Options) (result BurstResult, err error) {
+	if count <= 0 {
+		count = DefaultBurstSize
+	}
+	count = min(count, maxBurstSize)
+	result = BurstResult{Step: ttl, Answered: make([]bool, count)}
+
+	destAddr, err := destIP(dest)
+	if err != nil {
+		return
+	}
+	f, err := newFlow(destAddr, options.port(), options.NetworkInterface)
+	if err != nil {
+		return
+	}
+	defer f.close()
+	f.capture = options.Capture
+
+	port := options.port()
+	payload := make([]byte, options.payloadSize())
+	for i := 0; i < count; i++ {
+		if err = options.RateLimiter.Wait(ctx, destAddr); err != nil {
+			return
+		}
+		var pkt []byte
+		if pkt, err = newUDPPacket(destAddr, port, port, ttl, int(f.flowID<<6)+i+1, payload); err != nil {
+			return
+		}
+		if err = f.send(pkt, destAddr, port); err != nil {
+			err = fmt.Errorf("sendto error: %w", err)
+			return
+		}
+	}
+
+	recvBuff := make([]byte, recvBufferSize)
+	deadline := time.Now().Add(options.timeout())
+	for timeout := options.timeout(); timeout > 0; timeout = time.Until(deadline) {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = f.setRecvTimeout(timeout); err != nil {
+			return
+		}
+		n, e := f.recv(recvBuff)
+		if e != nil {
+			if e != syscall.EWOULDBLOCK {
+				time.Sleep(time.Millisecond * 10)
+			}
+			continue
+		}
+		hop, e := extractMessage(recvBuff[:n], false)
+		idx := hop.ID - int(f.flowID<<6) - 1
+		if e != nil || idx < 0 || idx >= count {
+			continue
+		}
+		result.Answered[idx] = true
+		result.Node = hop.Node.IP
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package gotraceroute
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestPathStats(t *testing.T) {
+	s := NewPathStats()
+	s.Add(testTrace("10.0.0.1", "*", "*", "192.0.2.4", "192.0.2.5"))
+	s.Add(testTrace("10.0.0.1", "*", "*", "192.0.2.4", "192.0.2.5"))
+	s.Add(testTrace("10.0.0.1", "*", "192.0.2.3", "192.0.2.4", "192.0.2.5"))
+	s.Add(testTrace("10.0.0.1", "*", "192.0.2.3", "192.0.2.4", "*"))
+
+	expected := []LossKind{LossNone, LossRateLimit, LossRateLimit, LossNone, LossForwardedPath}
+	hops := s.Hops()
+	if len(hops) != len(expected) || s.Traces() != 4 {
+		t.Fatalf("unexpected number of hops %v or traces %v", len(hops), s.Traces())
+	}
+	for i, h := range hops {
+		if h.LossKind != expected[i] {
+			t.Errorf("hop %v: expected %q, got %q", h.Step, expected[i], h.LossKind)
+		}
+	}
+	if h := hops[2]; h.Sent != 4 || h.Received != 2 || h.Loss != 0.5 || h.RTT.Count != 2 || len(h.Nodes) != 1 {
+		t.Errorf("unexpected hop stats %+v", h)
+	}
+
+	s.AddBurst(BurstResult{Step: 5, Answered: []bool{true, true, false, false, false}})
+	if h := s.Hops()[4]; h.LossKind != LossRateLimit {
+		t.Errorf("burst rate limiter pattern is ignored: %v", h)
+	}
+}
+
+func TestBurstResult(t *testing.T) {
+	cases := []struct {
+		answered []bool
+		limited  bool
+	}{
+		{[]bool{true, true, true, true}, false},
+		{[]bool{true, true, false, false}, true},
+		{[]bool{true, true, true, false}, false},
+		{[]bool{true, false, true, false}, false},
+		{[]bool{false, false, false, false}, false},
+	}
+	for _, c := range cases {
+		if (BurstResult{Answered: c.answered}).RateLimited() != c.limited {
+			t.Errorf("%v: expected rate limited %v", c.answered, c.limited)
+		}
+	}
+}
+
+func TestBurstProbe(t *testing.T) {
+	r, err := BurstProbe(context.Background(), "127.0.0.1", 1, 10, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, a := range r.Answered {
+		if !a {
+			t.Errorf("probe %v isn't answered", i)
+		}
+	}
+	if r.RateLimited() || !r.Node.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("unexpected burst result %+v", r)
+	}
+}
@@ -20,7 +20,7 @@ type udpHeader struct {
 	Checksum   uint16
 }
 
-func newUDPPacket(dst net.IP, srcPort, dstPort int, ttl, id int, payload []byte) []byte {
+func newUDPPacket(dst net.IP, srcPort, dstPort int, ttl, id int, payload []byte) ([]byte, error) {
 	ipHeader := ipv4.Header{
 		Version:  ipv4.Version,          // protocol version
 		Len:      ipv4.HeaderLen,        // header length
@@ -37,11 +37,14 @@ func newUDPPacket(dst net.IP, srcPort, dstPort int, ttl, id int, payload []byte)
 		Length:     uint16(8 + len(payload)),
 		// We'll leave checksum empty. It's optional in ipv4, and maybe the kernel will calculate it for us
 	}
-	b, _ := ipHeader.Marshal()
+	b, err := ipHeader.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("can't build the probe to %v: %w", dst, err)
+	}
 	data := bytes.NewBuffer(b)
 	_ = binary.Write(data, binary.BigEndian, udp)
 	data.Write(payload)
-	return data.Bytes()
+	return data.Bytes(), nil
 }
 
 func extractMessage(p []byte, resolveToName bool) (hop Hop, err error) {
@@ -82,9 +85,18 @@ func extractMessage(p []byte, resolveToName bool) (hop Hop, err error) {
 
 	hop = newHop(srcHeader.ID, srcHeader.Src, srcHeader.Dst, srcHeader.TTL)
 	hop.IcmpType = icmpType
+	hop.IcmpCode = int(p[replyHeader.Len+1])
+	hop.ReplyTTL = replyHeader.TTL
+	hop.ReplyIPID = replyHeader.ID
+	// the reply may be truncated by the socket filter or the capture snap length, so the size is taken from the header
+	hop.ReplySize = len(p) - replyHeader.Len
+	if replyHeader.TotalLen > len(p) {
+		hop.ReplySize = replyHeader.TotalLen - replyHeader.Len
+	}
 	hop.DstPort = int(dstPort)
 	hop.Node = Addr{
-		IP: replyHeader.Src,
+		IP:    replyHeader.Src,
+		Class: ClassifyAddr(replyHeader.Src),
 	}
 
 	if resolveToName {
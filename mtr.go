@@ -0,0 +1,141 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/ipv4"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// mtrVersion is the mtr version whose output formats are written, parsers of the CSV output check the version column
+const mtrVersion = "0.95"
+
+// mtrHostWidth is the width of the host column of the mtr report including the hop number prefix
+const mtrHostWidth = 33
+
+// MTRReport is the per-hop statistics of repeated traces written in the output formats of mtr:
+// the text report (mtr --report), JSON (mtr --json) and CSV (mtr --csv) with the same columns
+// Loss%, Snt, Last, Avg, Best, Wrst and StDev
+type MTRReport struct {
+	// Src is the local host name
+	Src string
+	// Dst is the traced target
+	Dst   string
+	Start time.Time
+	// Tests is the number of traces
+	Tests int
+	// PacketSize is the size of probe packets
+	PacketSize int
+	Hops       []HopStats
+}
+
+// NewMTRReport returns the report of the statistics of traces to target executed with options since start
+func NewMTRReport(target string, stats *PathStats, options Options, start time.Time) MTRReport {
+	src, _ := os.Hostname()
+	return MTRReport{Src: src, Dst: target, Start: start, Tests: stats.Traces(),
+		PacketSize: ipv4.HeaderLen + 8 + options.payloadSize(), Hops: stats.Hops()}
+}
+
+// mtrHub is the hop of the mtr JSON report
+type mtrHub struct {
+	Count int     `json:"count"`
+	Host  string  `json:"host"`
+	Loss  float64 `json:"Loss%"`
+	Snt   int     `json:"Snt"`
+	Last  float64 `json:"Last"`
+	Avg   float64 `json:"Avg"`
+	Best  float64 `json:"Best"`
+	Wrst  float64 `json:"Wrst"`
+	StDev float64 `json:"StDev"`
+}
+
+// mtrHubOf returns the values of the hop columns, durations are in milliseconds
+func mtrHubOf(s HopStats) mtrHub {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	h := mtrHub{Count: s.Step, Host: "???", Loss: s.Loss * 100, Snt: s.Sent}
+	if len(s.Nodes) > 0 {
+		h.Host = mtrName(s, 0)
+	}
+	if s.RTT.Count > 0 {
+		h.Last, h.Avg, h.Best, h.Wrst = ms(s.Last), ms(s.RTT.Avg), ms(s.RTT.Min), ms(s.RTT.Max)
+	}
+	// mtr calculates the sample standard deviation
+	if n := float64(s.RTT.Count); n > 1 {
+		h.StDev = ms(s.RTT.StdDev) * math.Sqrt(n/(n-1))
+	}
+	return h
+}
+
+// mtrName returns the host name of the hop node i or its address if the address isn't resolved
+func mtrName(s HopStats, i int) string {
+	if i < len(s.Hosts) && s.Hosts[i] != "" {
+		return s.Hosts[i]
+	}
+	return s.Nodes[i].String()
+}
+
+// WriteReport writes the report in the format of mtr --report, other nodes responded at the hop are listed
+// under the hop line like mtr lists load balanced paths
+func (r MTRReport) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Start: %s\n", r.Start.Format("2006-01-02T15:04:05-0700"))
+	fmt.Fprintf(&b, "%-*.*s%6s%6s %6s%6s%6s%6s%6s\n", mtrHostWidth, mtrHostWidth, "HOST: "+r.Src,
+		"Loss%", "Snt", "Last", "Avg", "Best", "Wrst", "StDev")
+	for _, s := range r.Hops {
+		h := mtrHubOf(s)
+		line := fmt.Sprintf(" %2d.|-- %s", h.Count, h.Host)
+		fmt.Fprintf(&b, "%-*.*s %4.1f%% %5d  %5.1f %5.1f %5.1f %5.1f %5.1f\n", mtrHostWidth, mtrHostWidth, line,
+			h.Loss, h.Snt, h.Last, h.Avg, h.Best, h.Wrst, h.StDev)
+		for i := 1; i < len(s.Nodes); i++ {
+			fmt.Fprintf(&b, "    |  `|-- %s\n", mtrName(s, i))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report in the format of mtr --json
+func (r MTRReport) WriteJSON(w io.Writer) error {
+	type mtrInfo struct {
+		Src        string `json:"src"`
+		Dst        string `json:"dst"`
+		Tos        int    `json:"tos"`
+		Psize      int    `json:"psize"`
+		Bitpattern int    `json:"bitpattern"`
+		Tests      int    `json:"tests"`
+	}
+	var report struct {
+		Report struct {
+			MTR  mtrInfo  `json:"mtr"`
+			Hubs []mtrHub `json:"hubs"`
+		} `json:"report"`
+	}
+	report.Report.MTR = mtrInfo{Src: r.Src, Dst: r.Dst, Psize: r.PacketSize, Tests: r.Tests}
+	report.Report.Hubs = []mtrHub{}
+	for _, s := range r.Hops {
+		report.Report.Hubs = append(report.Report.Hubs, mtrHubOf(s))
+	}
+	d, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(d, '\n'))
+	return err
+}
+
+// WriteCSV writes the report in the format of mtr --csv, the empty column after Snt is the mtr spacer column
+func (r MTRReport) WriteCSV(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("Mtr_Version,Start_Time,Status,Host,Hop,Ip,Loss%,Snt, ,Last,Avg,Best,Wrst,StDev,\n")
+	for _, s := range r.Hops {
+		h := mtrHubOf(s)
+		fmt.Fprintf(&b, "MTR.%s,%d,OK,%s,%d,%s,%.2f,%d,0,%.2f,%.2f,%.2f,%.2f,%.2f\n", mtrVersion, r.Start.Unix(), r.Dst,
+			h.Count, h.Host, h.Loss, h.Snt, h.Last, h.Avg, h.Best, h.Wrst, h.StDev)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
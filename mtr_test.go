@@ -0,0 +1,83 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMTRReport() MTRReport {
+	s := NewPathStats()
+	for i := 0; i < 4; i++ {
+		hops := testTrace("10.0.0.1", "*", "192.0.2.3", "192.0.2.4")
+		hops[0].Node.Host = "gateway"
+		hops[2].Elapsed = time.Duration(10+i) * time.Millisecond
+		if i == 3 {
+			hops[2].Node.IP = net.ParseIP("192.0.2.33")
+			hops[3].Success = false
+		}
+		s.Add(hops)
+	}
+	r := NewMTRReport("example.com", s, Options{PayloadSize: 36}, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	r.Src = "myhost"
+	return r
+}
+
+func TestMTRReport(t *testing.T) {
+	var b strings.Builder
+	if err := testMTRReport().WriteReport(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Start: 2024-05-01T10:00:00+0000
+HOST: myhost                      Loss%   Snt   Last   Avg  Best  Wrst StDev
+  1.|-- gateway                    0.0%     4    1.0   1.0   1.0   1.0   0.0
+  2.|-- ???                       100.0%     4    0.0   0.0   0.0   0.0   0.0
+  3.|-- 192.0.2.3                  0.0%     4   13.0  11.5  10.0  13.0   1.3
+    |  ` + "`" + `|-- 192.0.2.33
+  4.|-- 192.0.2.4                 25.0%     4    4.0   4.0   4.0   4.0   0.0
+`
+	if b.String() != expected {
+		t.Errorf("unexpected report:\n%v\nexpected:\n%v", b.String(), expected)
+	}
+}
+
+func TestMTRJSON(t *testing.T) {
+	var b strings.Builder
+	if err := testMTRReport().WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Report struct {
+			MTR struct {
+				Dst   string `json:"dst"`
+				Tests int    `json:"tests"`
+				Psize int    `json:"psize"`
+			} `json:"mtr"`
+			Hubs []map[string]interface{} `json:"hubs"`
+		} `json:"report"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &report); err != nil {
+		t.Fatal(err)
+	}
+	if m := report.Report.MTR; m.Dst != "example.com" || m.Tests != 4 || m.Psize != 64 || len(report.Report.Hubs) != 4 {
+		t.Fatalf("unexpected report %v", b.String())
+	}
+	hub := report.Report.Hubs[3]
+	if hub["count"] != 4.0 || hub["host"] != "192.0.2.4" || hub["Loss%"] != 25.0 || hub["Snt"] != 4.0 || hub["Avg"] != 4.0 {
+		t.Errorf("unexpected hub %v", hub)
+	}
+}
+
+func TestMTRCSV(t *testing.T) {
+	var b strings.Builder
+	if err := testMTRReport().WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != 6 || lines[0] != "Mtr_Version,Start_Time,Status,Host,Hop,Ip,Loss%,Snt, ,Last,Avg,Best,Wrst,StDev," ||
+		lines[3] != "MTR.0.95,1714557600,OK,example.com,3,192.0.2.3,0.00,4,0,13.00,11.50,10.00,13.00,1.29" {
+		t.Errorf("unexpected csv:\n%v", b.String())
+	}
+}
@@ -20,6 +20,25 @@ type Options struct {
 	PayloadSize      int
 	NetworkInterface string
 	DontResolve      bool
+	// ProbesPerHop is the number of probes sent to every hop, every probe is sent once and Retries isn't used
+	// if it's more than 1, results of all probes are in Hop.Probes
+	ProbesPerHop int
+	// AdaptiveTimeout makes the wait for a reply to be calculated for every TTL from RTTs of the previous hops,
+	// Timeout is used till the first reply is received, the calculated value is limited by MinTimeout and MaxTimeout
+	AdaptiveTimeout bool
+	MinTimeout      time.Duration
+	MaxTimeout      time.Duration
+	// IXPDB is used to mark hops on IXP peering LANs, if nil hops aren't marked
+	IXPDB *IXPDB
+	// ASNLookup resolves AS numbers of responders for metric sinks, if nil only IXP member ASNs are known
+	ASNLookup ASNLookup
+	// Collector receives probe level events, it may be shared between concurrent traceroutes
+	Collector Collector
+	// RateLimiter limits the rate of probes, it may be shared between concurrent traceroutes
+	RateLimiter *RateLimiter
+	// Capture records sent probes and all received ICMP packets including foreign ones,
+	// it may be shared between concurrent traceroutes or set per traceroute
+	Capture *PcapWriter
 }
 
 func (o *Options) port() int {
@@ -53,6 +72,28 @@ func (o *Options) timeout() time.Duration {
 	return o.Timeout
 }
 
+func (o *Options) minTimeout() time.Duration {
+	if o.MinTimeout == 0 {
+		o.MinTimeout = time.Millisecond * DefaultMinTimeoutMs
+	}
+	return o.MinTimeout
+}
+
+func (o *Options) maxTimeout() time.Duration {
+	if o.MaxTimeout == 0 {
+		o.MaxTimeout = time.Millisecond * DefaultMaxTimeoutMs
+	}
+	return o.MaxTimeout
+}
+
+// probeTimeout returns the time to wait for a reply to the probe retried the retry number of times
+func (o *Options) probeTimeout(rtt *rttEstimator, retry int) time.Duration {
+	if !o.AdaptiveTimeout {
+		return o.timeout()
+	}
+	return rtt.timeout(retry)
+}
+
 func (o *Options) retries() int {
 	if o.Retries == 0 {
 		o.Retries = DefaultRetries
@@ -60,6 +101,23 @@ func (o *Options) retries() int {
 	return o.Retries
 }
 
+func (o *Options) probesPerHop() int {
+	if o.ProbesPerHop <= 0 {
+		o.ProbesPerHop = 1
+	}
+	return o.ProbesPerHop
+}
+
 func (o *Options) payloadSize() int {
+	if o.PayloadSize < 0 {
+		o.PayloadSize = 0
+	}
 	return o.PayloadSize
 }
+
+func (o *Options) collector() Collector {
+	if o.Collector == nil {
+		return nopCollector{}
+	}
+	return o.Collector
+}
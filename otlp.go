@@ -0,0 +1,155 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// otlpScope is the instrumentation scope of the metrics
+const otlpScope = "github.com/archer-v/gotraceroute"
+
+// OTLP JSON encoding of metrics, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding,
+// 64-bit integers are encoded as strings
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []otlpAttribute `json:"attributes"`
+	TimeUnixNano string          `json:"timeUnixNano"`
+	AsDouble     *float64        `json:"asDouble,omitempty"`
+	AsInt        *string         `json:"asInt,omitempty"`
+}
+
+type otlpMetric struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Gauge       struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+}
+
+// OTLPSink writes data points as OpenTelemetry gauges in the OTLP JSON encoding: every Flush writes
+// an ExportMetricsServiceRequest on a single line, the format of the collector otlpjsonfile receiver,
+// a line can be posted to the /v1/metrics endpoint of an OTLP/HTTP collector as is.
+// Hop gauges traceroute.hop.answered and traceroute.hop.rtt have target, hop, responder and asn attributes,
+// trace gauges traceroute.hops, traceroute.reached and traceroute.duration have target and dst attributes
+type OTLPSink struct {
+	w       io.Writer
+	metrics []*otlpMetric
+}
+
+// NewOTLPSink returns the sink writing OTLP JSON requests to w
+func NewOTLPSink(w io.Writer) *OTLPSink {
+	return &OTLPSink{w: w}
+}
+
+func otlpString(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+func otlpInt(i int) otlpValue {
+	s := strconv.Itoa(i)
+	return otlpValue{IntValue: &s}
+}
+
+// add adds the data point of the gauge, value is float64 or int
+func (s *OTLPSink) add(name, unit string, attrs []otlpAttribute, t time.Time, value interface{}) {
+	var m *otlpMetric
+	for _, o := range s.metrics {
+		if o.Name == name {
+			m = o
+		}
+	}
+	if m == nil {
+		m = &otlpMetric{Name: name, Unit: unit}
+		s.metrics = append(s.metrics, m)
+	}
+	p := otlpDataPoint{Attributes: attrs, TimeUnixNano: strconv.FormatInt(t.UnixNano(), 10)}
+	switch v := value.(type) {
+	case float64:
+		p.AsDouble = &v
+	case int:
+		i := strconv.Itoa(v)
+		p.AsInt = &i
+	}
+	m.Gauge.DataPoints = append(m.Gauge.DataPoints, p)
+}
+
+// WriteHop adds the answered gauge of the hop and the RTT gauge of the answered hop
+func (s *OTLPSink) WriteHop(target string, h Hop, asn int) error {
+	var attrs []otlpAttribute
+	for _, tag := range metricTags(target, h, asn) {
+		if tag[0] == "hop" {
+			attrs = append(attrs, otlpAttribute{Key: tag[0], Value: otlpInt(h.Step)})
+		} else {
+			attrs = append(attrs, otlpAttribute{Key: tag[0], Value: otlpString(tag[1])})
+		}
+	}
+	answered := 0
+	if h.Success {
+		answered = 1
+		s.add("traceroute.hop.rtt", "ms", attrs, hopTime(h), float64(h.Elapsed.Microseconds())/1000)
+	}
+	s.add("traceroute.hop.answered", "1", attrs, hopTime(h), answered)
+	return nil
+}
+
+// WriteSummary adds the gauges of the trace
+func (s *OTLPSink) WriteSummary(t TraceSummary) error {
+	attrs := []otlpAttribute{{Key: "target", Value: otlpString(t.Target)}}
+	if t.Dst != nil {
+		attrs = append(attrs, otlpAttribute{Key: "dst", Value: otlpString(t.Dst.String())})
+	}
+	reached := 0
+	if t.Reached {
+		reached = 1
+	}
+	s.add("traceroute.hops", "1", attrs, t.Started, t.Hops)
+	s.add("traceroute.reached", "1", attrs, t.Started, reached)
+	s.add("traceroute.duration", "ms", attrs, t.Started, float64(t.Duration.Microseconds())/1000)
+	return nil
+}
+
+// Flush writes the request with the data points added since the previous Flush
+func (s *OTLPSink) Flush() error {
+	if len(s.metrics) == 0 {
+		return nil
+	}
+	type scopeMetrics struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Metrics []*otlpMetric `json:"metrics"`
+	}
+	type resourceMetrics struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+	}
+	var rm resourceMetrics
+	rm.Resource.Attributes = []otlpAttribute{{Key: "service.name", Value: otlpString("gotraceroute")}}
+	sm := scopeMetrics{Metrics: s.metrics}
+	sm.Scope.Name = otlpScope
+	rm.ScopeMetrics = []scopeMetrics{sm}
+
+	d, err := json.Marshal(struct {
+		ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+	}{[]resourceMetrics{rm}})
+	if err != nil {
+		return err
+	}
+	s.metrics = nil
+	_, err = s.w.Write(append(d, '\n'))
+	return err
+}
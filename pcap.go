@@ -0,0 +1,200 @@
+package gotraceroute
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// pcap file format constants, see https://www.ietf.org/archive/id/draft-ietf-opsawg-pcap-01.html
+const (
+	pcapMagicNano    = 0xa1b23c4d
+	pcapVersionMajor = 2
+	pcapVersionMinor = 4
+	pcapSnapLen      = 65535
+	// pcapLinkTypeRaw is the link type of packets beginning with the IP header
+	pcapLinkTypeRaw = 101
+)
+
+// PcapWriter writes packets to a pcap file with nanosecond timestamps.
+// Packets are written as raw IPv4 packets, e.g. probes with their IP headers and ICMP replies with IP headers
+// as they are read from the raw socket. PcapWriter is safe for concurrent use, so it may be shared by
+// concurrent traceroutes, or every traceroute may have its own capture set in its Options.
+type PcapWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewPcapWriter writes the pcap file header to w and returns the writer of packets
+func NewPcapWriter(w io.Writer) (*PcapWriter, error) {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:4], pcapMagicNano)
+	binary.LittleEndian.PutUint16(header[4:6], pcapVersionMajor)
+	binary.LittleEndian.PutUint16(header[6:8], pcapVersionMinor)
+	binary.LittleEndian.PutUint32(header[16:20], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:24], pcapLinkTypeRaw)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &PcapWriter{w: w}, nil
+}
+
+// WritePacket writes the raw IP packet captured at the time t
+func (p *PcapWriter) WritePacket(t time.Time, data []byte) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = append(p.buf[:0], make([]byte, 16)...)
+	binary.LittleEndian.PutUint32(p.buf[0:4], uint32(t.Unix()))
+	binary.LittleEndian.PutUint32(p.buf[4:8], uint32(t.Nanosecond()))
+	binary.LittleEndian.PutUint32(p.buf[8:12], uint32(len(data)))
+	binary.LittleEndian.PutUint32(p.buf[12:16], uint32(len(data)))
+	p.buf = append(p.buf, data...)
+	// the record is written at once, so records of concurrent writers aren't interleaved even if w is shared
+	_, err := p.w.Write(p.buf)
+	return err
+}
+
+// pcap link types of captures PcapReader can read
+const (
+	pcapLinkTypeNull     = 0
+	pcapLinkTypeEthernet = 1
+	pcapLinkTypeLinuxSLL = 113
+	pcapLinkTypeIPv4     = 228
+	pcapLinkTypeSLL2     = 276
+)
+
+const pcapMagicMicro = 0xa1b2c3d4
+const pcapngMagic = 0x0a0d0d0a
+
+const etherTypeIPv4 = 0x0800
+
+// PcapReader reads IPv4 packets from a pcap file written by PcapWriter or by tcpdump,
+// ethernet, linux cooked (tcpdump -i any), BSD loopback and raw IP link types are supported
+type PcapReader struct {
+	r         io.Reader
+	order     binary.ByteOrder
+	nano      bool
+	linkType  uint32
+	header    [16]byte
+	packetBuf []byte
+}
+
+// NewPcapReader reads the pcap file header from r and returns the reader of packets
+func NewPcapReader(r io.Reader) (*PcapReader, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("can't read pcap header: %w", err)
+	}
+	p := &PcapReader{r: r}
+	switch {
+	case binary.LittleEndian.Uint32(header) == pcapMagicMicro:
+		p.order = binary.LittleEndian
+	case binary.LittleEndian.Uint32(header) == pcapMagicNano:
+		p.order, p.nano = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(header) == pcapMagicMicro:
+		p.order = binary.BigEndian
+	case binary.BigEndian.Uint32(header) == pcapMagicNano:
+		p.order, p.nano = binary.BigEndian, true
+	case binary.LittleEndian.Uint32(header) == pcapngMagic:
+		return nil, errors.New("pcapng format isn't supported, convert the file with editcap -F pcap")
+	default:
+		return nil, errors.New("not a pcap file")
+	}
+	p.linkType = p.order.Uint32(header[20:24]) & 0x0fffffff
+	switch p.linkType {
+	case pcapLinkTypeRaw, pcapLinkTypeIPv4, pcapLinkTypeEthernet, pcapLinkTypeLinuxSLL, pcapLinkTypeSLL2, pcapLinkTypeNull:
+	default:
+		return nil, fmt.Errorf("unsupported pcap link type %d", p.linkType)
+	}
+	return p, nil
+}
+
+// ReadPacket returns the next IPv4 packet and its capture time, packets of other protocols are skipped.
+// io.EOF is returned at the end of the file. The packet data is valid till the next call
+func (p *PcapReader) ReadPacket() (t time.Time, data []byte, err error) {
+	for {
+		if _, err = io.ReadFull(p.r, p.header[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = errors.New("truncated pcap record header")
+			}
+			return
+		}
+		sec, frac := p.order.Uint32(p.header[0:4]), p.order.Uint32(p.header[4:8])
+		if !p.nano {
+			frac *= 1000
+		}
+		t = time.Unix(int64(sec), int64(frac))
+		n := p.order.Uint32(p.header[8:12])
+		if n > pcapSnapLen*4 {
+			err = fmt.Errorf("invalid pcap record length %d", n)
+			return
+		}
+		if cap(p.packetBuf) < int(n) {
+			p.packetBuf = make([]byte, n)
+		}
+		data = p.packetBuf[:n]
+		if _, err = io.ReadFull(p.r, data); err != nil {
+			err = fmt.Errorf("truncated pcap record: %w", err)
+			return
+		}
+		if data = p.ipPayload(data); data != nil {
+			return
+		}
+	}
+}
+
+// ipPayload strips the link layer header and returns the IPv4 packet or nil if it isn't an IPv4 packet
+func (p *PcapReader) ipPayload(data []byte) []byte {
+	switch p.linkType {
+	case pcapLinkTypeEthernet:
+		if len(data) < 14 {
+			return nil
+		}
+		etherType, data := binary.BigEndian.Uint16(data[12:14]), data[14:]
+		// skip 802.1Q and 802.1ad VLAN tags
+		for (etherType == 0x8100 || etherType == 0x88a8) && len(data) >= 4 {
+			etherType, data = binary.BigEndian.Uint16(data[2:4]), data[4:]
+		}
+		if etherType != etherTypeIPv4 {
+			return nil
+		}
+		return ipv4Packet(data)
+	case pcapLinkTypeLinuxSLL:
+		if len(data) < 16 || binary.BigEndian.Uint16(data[14:16]) != etherTypeIPv4 {
+			return nil
+		}
+		return ipv4Packet(data[16:])
+	case pcapLinkTypeSLL2:
+		if len(data) < 20 || binary.BigEndian.Uint16(data[0:2]) != etherTypeIPv4 {
+			return nil
+		}
+		return ipv4Packet(data[20:])
+	case pcapLinkTypeNull:
+		// the address family is in the host byte order of the capturing machine
+		if len(data) < 4 || (binary.LittleEndian.Uint32(data) != syscall.AF_INET && binary.BigEndian.Uint32(data) != syscall.AF_INET) {
+			return nil
+		}
+		return ipv4Packet(data[4:])
+	default:
+		return ipv4Packet(data)
+	}
+}
+
+// ipv4Packet returns the IPv4 packet without the link layer padding or nil if data isn't an IPv4 packet
+func ipv4Packet(data []byte) []byte {
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return nil
+	}
+	if l := int(binary.BigEndian.Uint16(data[2:4])); l >= 20 && l < len(data) {
+		data = data[:l]
+	}
+	return data
+}
@@ -0,0 +1,47 @@
+package gotraceroute
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func TestCapture(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewPcapWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = RunBlock("127.0.0.1", Options{Capture: w}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	if len(data) < 24 || binary.LittleEndian.Uint32(data) != pcapMagicNano || binary.LittleEndian.Uint32(data[20:]) != pcapLinkTypeRaw {
+		t.Fatalf("invalid pcap header %x", data[:min(len(data), 24)])
+	}
+	var packets [][]byte
+	for p := data[24:]; len(p) >= 16; {
+		n := int(binary.LittleEndian.Uint32(p[8:]))
+		packets = append(packets, p[16:16+n])
+		p = p[16+n:]
+	}
+	if len(packets) != 2 {
+		t.Fatalf("expected the probe and the reply, got %v packets", len(packets))
+	}
+	probe, reply := packets[0], packets[1]
+	if probe[9] != 17 || !net.IP(probe[12:16]).Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("unexpected probe packet %x", probe)
+	}
+	var sum uint32
+	for i := 0; i < 20; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(probe[i:]))
+	}
+	if sum>>16+sum&0xffff != 0xffff {
+		t.Errorf("invalid probe header checksum %x", probe[10:12])
+	}
+	if hop, err := extractMessage(reply, false); err != nil || !hop.Node.IP.Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Errorf("unexpected reply packet %x: %v", reply, err)
+	}
+}
@@ -0,0 +1,138 @@
+package gotraceroute
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+const DefaultRateLimitPrefixLen = 24
+
+// maxIdlePrefixBuckets is the number of per-prefix buckets kept before idle ones are removed
+const maxIdlePrefixBuckets = 4096
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimiter limits the rate of probe packets with a token bucket. A single RateLimiter is supposed to be shared
+// by all traceroutes of a process through Options, so the overall rate is limited whatever the number of concurrent
+// flows is, RunMany creates one for the batch from BatchOptions.ProbesPerSecond.
+// Besides the global limit an optional limit per destination prefix can be set, it prevents
+// bursting to routers of the same network when many targets in it are traced at once.
+// nil RateLimiter doesn't limit anything.
+type RateLimiter struct {
+	// rate is the number of tokens added per second, 0 means the rate isn't limited
+	rate   float64
+	burst  float64
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	prefixLen   int
+	prefixRate  float64
+	prefixBurst int
+	// prefixes are limiters of destination prefixes without their own prefix limits
+	prefixes map[string]*RateLimiter
+}
+
+// NewRateLimiter returns the limiter of rate probes per second with the burst of probes sent at once,
+// rate 0 means the global rate isn't limited
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: max(rate, 0), burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// SetPrefixLimit limits the rate of probes to destinations of every prefix of prefixLen bits,
+// prefixLen 0 means DefaultRateLimitPrefixLen, rate 0 disables the per-prefix limit
+func (l *RateLimiter) SetPrefixLimit(prefixLen int, rate float64, burst int) {
+	if prefixLen == 0 {
+		prefixLen = DefaultRateLimitPrefixLen
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prefixLen = prefixLen
+	l.prefixRate = rate
+	l.prefixBurst = burst
+	l.prefixes = map[string]*RateLimiter{}
+}
+
+// Wait blocks till a probe to the dst address is allowed to be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, dst net.IP) error {
+	if l == nil {
+		return nil
+	}
+	// the prefix token is taken first, so waiting for a busy prefix doesn't hold global tokens
+	if p := l.prefixLimiter(dst); p != nil {
+		if err := sleep(ctx, p.reserve()); err != nil {
+			return err
+		}
+	}
+	return sleep(ctx, l.reserve())
+}
+
+// reserve takes a token and returns the time to wait till the token is available
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate == 0 {
+		return 0
+	}
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// idle returns true if the bucket is full, e.g. it hasn't been used for a while
+func (l *RateLimiter) idle(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tokens+now.Sub(l.last).Seconds()*l.rate >= l.burst
+}
+
+// prefixLimiter returns the limiter of the dst prefix or nil if the per-prefix rate isn't limited
+func (l *RateLimiter) prefixLimiter(dst net.IP) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.prefixRate <= 0 {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if dst.To4() != nil {
+		dst = dst.To4()
+		bits = 8 * net.IPv4len
+	}
+	key := dst.Mask(net.CIDRMask(min(l.prefixLen, bits), bits)).String()
+	p, ok := l.prefixes[key]
+	if !ok {
+		if len(l.prefixes) >= maxIdlePrefixBuckets {
+			now := time.Now()
+			for k, o := range l.prefixes {
+				if o.idle(now) {
+					delete(l.prefixes, k)
+				}
+			}
+		}
+		p = NewRateLimiter(l.prefixRate, l.prefixBurst)
+		l.prefixes[key] = p
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package gotraceroute
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	waitN := func(l *RateLimiter, dst string, n int) time.Duration {
+		started := time.Now()
+		for i := 0; i < n; i++ {
+			if err := l.Wait(ctx, net.ParseIP(dst)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return time.Since(started)
+	}
+
+	var l *RateLimiter
+	if d := waitN(l, "192.0.2.1", 100); d > 10*time.Millisecond {
+		t.Errorf("nil limiter waited for %v", d)
+	}
+
+	l = NewRateLimiter(100, 1)
+	if d := waitN(l, "192.0.2.1", 11); d < 90*time.Millisecond {
+		t.Errorf("11 probes at 100 pps were sent in %v", d)
+	}
+
+	l = NewRateLimiter(0, 0)
+	l.SetPrefixLimit(24, 50, 2)
+	if d := waitN(l, "192.0.2.1", 2) + waitN(l, "198.51.100.1", 2); d > 10*time.Millisecond {
+		t.Errorf("burst to different prefixes waited for %v", d)
+	}
+	if d := waitN(l, "192.0.2.200", 3); d < 50*time.Millisecond {
+		t.Errorf("3 probes to the same prefix at 50 pps were sent in %v", d)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cancelled, net.ParseIP("192.0.2.1")); err == nil {
+		t.Error("wait with cancelled context should fail")
+	}
+}
@@ -0,0 +1,187 @@
+package gotraceroute
+
+import (
+	"encoding/binary"
+	"errors"
+	"golang.org/x/net/ipv4"
+	"io"
+	"net"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// ReplayedTrace is a traceroute rebuilt from captured packets
+type ReplayedTrace struct {
+	Dst net.IP
+	// FlowID is the flow id of the traceroute probes
+	FlowID int
+	Hops   []Hop
+}
+
+// replayFlowKey identifies probes of one traceroute
+type replayFlowKey struct {
+	dst    string
+	flowID int
+}
+
+type replayProbe struct {
+	id   int
+	ttl  int
+	sent time.Time
+}
+
+type replayReply struct {
+	hop      Hop
+	received time.Time
+}
+
+type replayFlow struct {
+	src     net.IP
+	dst     net.IP
+	flowID  int
+	probes  []replayProbe
+	replies []replayReply
+}
+
+// ReplayPcap reads a capture of probes and ICMP replies written with Options.Capture or by tcpdump
+// and rebuilds traceroutes the same way Run does: replies are decoded with the same code and matched to probes
+// by the destination and the probe id, so the hops are the same the traceroute produced when it was running.
+// Only UDP packets from and to options.Port with the probe id layout are taken as probes.
+// Traces are returned in order of their first probes. options.DontResolve and options.IXPDB are applied to hops
+func ReplayPcap(r io.Reader, options Options) (traces []ReplayedTrace, err error) {
+	pr, err := NewPcapReader(r)
+	if err != nil {
+		return
+	}
+
+	flows := map[replayFlowKey]*replayFlow{}
+	var order []*replayFlow
+	for {
+		t, data, e := pr.ReadPacket()
+		if errors.Is(e, io.EOF) {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		switch data[9] {
+		case syscall.IPPROTO_UDP:
+			if !isProbe(data, options.port()) {
+				continue
+			}
+			id := int(data[4])<<8 | int(data[5])
+			dst := net.IP(append([]byte(nil), data[16:20]...))
+			key := replayFlowKey{dst: dst.String(), flowID: id >> 6}
+			f, ok := flows[key]
+			if !ok {
+				f = &replayFlow{src: net.IP(append([]byte(nil), data[12:16]...)), dst: dst, flowID: id >> 6}
+				flows[key] = f
+				order = append(order, f)
+			}
+			f.probes = append(f.probes, replayProbe{id: id, ttl: int(data[8]), sent: t})
+		case syscall.IPPROTO_ICMP:
+			hop, e := extractMessage(data, false)
+			if e != nil || hop.Dst.IP == nil || hop.DstPort != options.port() {
+				continue
+			}
+			if f, ok := flows[replayFlowKey{dst: hop.Dst.IP.String(), flowID: hop.ID >> 6}]; ok {
+				f.replies = append(f.replies, replayReply{hop: hop, received: t})
+			}
+		}
+	}
+
+	for _, f := range order {
+		traces = append(traces, ReplayedTrace{Dst: f.dst, FlowID: f.flowID, Hops: f.hops(options)})
+	}
+	return
+}
+
+// isProbe returns true if the UDP packet has the shape of a traceroute probe: the source and destination ports
+// are the probe port and the IP ID has a packet index run assigns (1..62, then 0 when it wraps), other UDP traffic
+// like DNS lookups is captured by tcpdump as well
+func isProbe(data []byte, port int) bool {
+	hl := int(data[0]&0x0f) * 4
+	if hl < ipv4.HeaderLen || len(data) < hl+8 {
+		return false
+	}
+	srcPort := int(binary.BigEndian.Uint16(data[hl:]))
+	dstPort := int(binary.BigEndian.Uint16(data[hl+2:]))
+	idx := int(data[5]) & (1<<6 - 1)
+	return srcPort == port && dstPort == port && idx < 1<<6-1
+}
+
+// hops rebuilds the hops of the flow: a hop is the first reply to any probe of its TTL,
+// a TTL without replies is a lost hop
+func (f *replayFlow) hops(options Options) (hops []Hop) {
+	sort.SliceStable(f.probes, func(i, j int) bool { return f.probes[i].sent.Before(f.probes[j].sent) })
+	sort.SliceStable(f.replies, func(i, j int) bool { return f.replies[i].received.Before(f.replies[j].received) })
+
+	answered := map[int]Hop{}
+	for _, r := range f.replies {
+		// the probe id is reused every 63 probes, the reply belongs to the latest probe with the id sent before it
+		var p *replayProbe
+		latest := 0
+		for i := range f.probes {
+			if f.probes[i].sent.After(r.received) {
+				break
+			}
+			latest = f.probes[i].id
+			if f.probes[i].id == r.hop.ID {
+				p = &f.probes[i]
+			}
+		}
+		if p == nil {
+			continue
+		}
+		if _, ok := answered[p.ttl]; ok {
+			continue
+		}
+		hop := r.hop
+		hop.Success = true
+		hop.Step = p.ttl
+		hop.Sent = p.sent
+		hop.Received = r.received
+		hop.Elapsed = r.received.Sub(p.sent)
+		hop.Late = p.id != latest
+		// the attempt is the number of probes of the ttl sent before the answered one
+		for i := 0; &f.probes[i] != p; i++ {
+			if f.probes[i].ttl == p.ttl {
+				hop.Attempt++
+			}
+		}
+		answered[p.ttl] = hop
+	}
+
+	var ttls []int
+	last := map[int]int{}
+	for i, p := range f.probes {
+		if _, ok := last[p.ttl]; !ok {
+			ttls = append(ttls, p.ttl)
+		}
+		last[p.ttl] = i
+	}
+	sort.Ints(ttls)
+	for _, ttl := range ttls {
+		hop, ok := answered[ttl]
+		if !ok {
+			// the hop was given up on when the next probe was sent
+			i := last[ttl]
+			p := f.probes[i]
+			hop = newHop(f.flowID, f.src, f.dst, ttl)
+			hop.Sent = p.sent
+			if i+1 < len(f.probes) {
+				hop.Elapsed = f.probes[i+1].sent.Sub(p.sent)
+			}
+		} else {
+			if !options.DontResolve {
+				if names, _ := net.LookupAddr(hop.Node.IP.String()); len(names) > 0 {
+					hop.Node.Host = names[0]
+				}
+			}
+			hop.IXP = options.IXPDB.Lookup(hop.Node.IP)
+		}
+		hops = append(hops, hop)
+	}
+	return
+}
@@ -0,0 +1,174 @@
+package gotraceroute
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestReplayCapture(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewPcapWriter(&buf)
+	hops, err := RunBlock("127.0.0.1", Options{Capture: w, DontResolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traces, err := ReplayPcap(&buf, Options{DontResolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 || len(traces[0].Hops) != len(hops) {
+		t.Fatalf("unexpected replayed traces %v", traces)
+	}
+	for i, h := range traces[0].Hops {
+		if h.Step != hops[i].Step || h.Success != hops[i].Success || !h.Node.IP.Equal(hops[i].Node.IP) ||
+			h.IcmpType != hops[i].IcmpType || h.ReplyTTL != hops[i].ReplyTTL {
+			t.Errorf("replayed hop %v differs from %v", h.StringHuman(), hops[i].StringHuman())
+		}
+	}
+}
+
+// testReply returns the ICMP reply of the type from the node to the probe
+func testReply(node string, typ ipv4.ICMPType, probe []byte) []byte {
+	msg := icmp.Message{Type: typ, Body: &icmp.TimeExceeded{Data: probe[:28]}}
+	if typ == ipv4.ICMPTypeDestinationUnreachable {
+		msg.Body = &icmp.DstUnreach{Data: probe[:28]}
+	}
+	body, _ := msg.Marshal(nil)
+	h := ipv4.Header{Version: 4, Len: 20, TotalLen: 20 + len(body), TTL: 60, Protocol: 1, Src: net.ParseIP(node), Dst: net.IPv4(10, 0, 0, 100)}
+	b, _ := h.Marshal()
+	return append(b, body...)
+}
+
+// testUDPPacket returns the probe packet to the IPv4 address dst
+func testUDPPacket(dst net.IP, ttl, id int) []byte {
+	pkt, _ := newUDPPacket(dst, DefaultPort, DefaultPort, ttl, id, nil)
+	return pkt
+}
+
+// testPacket is the packet captured at the time after the capture start
+type testPacket struct {
+	at   time.Duration
+	data []byte
+}
+
+// testPcap returns the Ethernet pcap of the IPv4 packets preceded by an ARP frame
+func testPcap(started time.Time, packets []testPacket) *bytes.Buffer {
+	var buf bytes.Buffer
+	header := make([]byte, 24)
+	binary.BigEndian.PutUint32(header, pcapMagicMicro)
+	binary.BigEndian.PutUint32(header[20:], pcapLinkTypeEthernet)
+	buf.Write(header)
+	write := func(at time.Duration, etherType uint16, data []byte) {
+		ts := started.Add(at)
+		frame := append(make([]byte, 14), data...)
+		binary.BigEndian.PutUint16(frame[12:], etherType)
+		rec := make([]byte, 16)
+		binary.BigEndian.PutUint32(rec[0:], uint32(ts.Unix()))
+		binary.BigEndian.PutUint32(rec[4:], uint32(ts.Nanosecond()/1000))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(frame)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(frame)))
+		buf.Write(append(rec, frame...))
+	}
+	write(0, 0x0806, make([]byte, 28))
+	for _, p := range packets {
+		write(p.at, etherTypeIPv4, p.data)
+	}
+	return &buf
+}
+
+func TestExtractMessageTruncated(t *testing.T) {
+	// the reply with ICMP extensions is cut off by the socket filter, its size is the size of the whole reply
+	reply := testReply("10.0.0.1", ipv4.ICMPTypeTimeExceeded, testUDPPacket(net.ParseIP("192.0.2.1"), 1, 5<<6+1))
+	size := len(reply) - ipv4.HeaderLen + 300
+	binary.BigEndian.PutUint16(reply[2:], uint16(len(reply)+300))
+	hop, err := extractMessage(reply, false)
+	if err != nil || hop.ReplySize != size {
+		t.Errorf("expected the reply size %v, got %v: %v", size, hop.ReplySize, err)
+	}
+}
+
+func TestReplayEthernet(t *testing.T) {
+	dst := net.ParseIP("192.0.2.1")
+	started := time.Unix(1700000000, 0)
+	ms := time.Millisecond
+	id := 5<<6 + 1
+	probe := func(ttl int) []byte {
+		id++
+		return testUDPPacket(dst, ttl, id-1)
+	}
+	p1, p2, p2retry, p3 := probe(1), probe(2), probe(2), probe(3)
+	packets := []testPacket{
+		{0, p1},
+		{10 * ms, testReply("10.0.0.1", ipv4.ICMPTypeTimeExceeded, p1)},
+		{100 * ms, p2},
+		{300 * ms, p2retry},
+		{400 * ms, testReply("198.51.100.1", ipv4.ICMPTypeTimeExceeded, testUDPPacket(dst, 1, 7<<6+1))},
+		{500 * ms, p3},
+		{520 * ms, testReply("10.0.0.2", ipv4.ICMPTypeTimeExceeded, p2)},
+		{530 * ms, testReply("192.0.2.1", ipv4.ICMPTypeDestinationUnreachable, p3)},
+	}
+	// unrelated UDP traffic like DNS lookups of the tool isn't taken for probes
+	dnsQuery, _ := newUDPPacket(dst, 40000, 53, 64, 5<<6+2, []byte("query"))
+	dnsReply, _ := newUDPPacket(net.IPv4(10, 0, 0, 100), 53, 40000, 60, 1234, []byte("reply"))
+	otherPort, _ := newUDPPacket(dst, 5353, 5353, 3, 5<<6+3, nil)
+	packets = append(packets, []testPacket{
+		{150 * ms, dnsQuery},
+		{160 * ms, dnsReply},
+		{170 * ms, otherPort},
+		{180 * ms, testReply("10.0.0.9", ipv4.ICMPTypeDestinationUnreachable, dnsQuery)},
+	}...)
+
+	traces, err := ReplayPcap(testPcap(started, packets), Options{DontResolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 || traces[0].FlowID != 5 || len(traces[0].Hops) != 3 {
+		t.Fatalf("unexpected replayed traces %+v", traces)
+	}
+	expected := []struct {
+		node    string
+		elapsed time.Duration
+		late    bool
+	}{
+		{"10.0.0.1", 10 * ms, false},
+		{"10.0.0.2", 420 * ms, true},
+		{"192.0.2.1", 30 * ms, false},
+	}
+	for i, h := range traces[0].Hops {
+		e := expected[i]
+		if !h.Success || h.Step != i+1 || !h.Node.IP.Equal(net.ParseIP(e.node)) || h.Elapsed != e.elapsed || h.Late != e.late {
+			t.Errorf("hop %v: expected %v %v late %v, got %v late %v", i+1, e.node, e.elapsed, e.late, h.StringHuman(), h.Late)
+		}
+	}
+}
+
+func TestReplayPacketIndexWrap(t *testing.T) {
+	// the packet index cycles 1..62 and 0, so probes past the 63rd reuse the indexes
+	dst := net.ParseIP("192.0.2.1")
+	var packets []testPacket
+	for i := 0; i < 70; i++ {
+		p := testUDPPacket(dst, i+1, 3<<6+(i+1)%(1<<6-1))
+		at := time.Duration(i) * time.Second
+		packets = append(packets, testPacket{at, p}, testPacket{at + time.Millisecond, testReply(fmt.Sprintf("10.0.0.%v", i+1), ipv4.ICMPTypeTimeExceeded, p)})
+	}
+
+	traces, err := ReplayPcap(testPcap(time.Unix(1700000000, 0), packets), Options{DontResolve: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 1 || len(traces[0].Hops) != 70 {
+		t.Fatalf("expected a trace of 70 hops, got %v", traces)
+	}
+	for i, h := range traces[0].Hops {
+		if !h.Success || h.Step != i+1 || !h.Node.IP.Equal(net.ParseIP(fmt.Sprintf("10.0.0.%v", i+1))) {
+			t.Errorf("hop %v: unexpected %v", i+1, h.StringHuman())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gotraceroute
+
+import (
+	"strconv"
+	"time"
+)
+
+// MetricSink turns hops and trace summaries into data points of a metrics backend.
+// Data points may be buffered till Flush, a sink is used by a single goroutine.
+// InfluxSink and OTLPSink are the implementations, other backends may implement the interface
+// and be used with SinkEncoder the same way
+type MetricSink interface {
+	// WriteHop writes data points of the hop of the trace to target, asn is the AS number of the responder,
+	// 0 if it's unknown
+	WriteHop(target string, h Hop, asn int) error
+	// WriteSummary writes data points of the finished trace
+	WriteSummary(s TraceSummary) error
+	// Flush writes the buffered data points
+	Flush() error
+}
+
+// metricTags returns the tags every hop data point has: the target, the hop number, the responder address
+// and the AS number of the responder, the last two are omitted if they are unknown
+func metricTags(target string, h Hop, asn int) (tags [][2]string) {
+	tags = append(tags, [2]string{"target", target}, [2]string{"hop", strconv.Itoa(h.Step)})
+	if h.Success {
+		tags = append(tags, [2]string{"responder", h.Node.IP.String()})
+	}
+	if asn != 0 {
+		tags = append(tags, [2]string{"asn", strconv.Itoa(asn)})
+	}
+	return
+}
+
+// hopASN returns the AS number of the hop responder: the IXP member ASN for responders on peering LANs,
+// the peering LAN prefix is originated by the exchange itself, otherwise the ASN the lookup returns
+func hopASN(h Hop, asns ASNLookup) int {
+	switch {
+	case !h.Success:
+		return 0
+	case h.IXP != nil && h.IXP.MemberASN != 0:
+		return h.IXP.MemberASN
+	case asns != nil:
+		return asns.LookupASN(h.Node.IP)
+	}
+	return 0
+}
+
+// hopTime returns the timestamp of hop data points
+func hopTime(h Hop) time.Time {
+	if h.Sent.IsZero() {
+		return time.Now()
+	}
+	return h.Sent
+}
+
+// SinkEncoder writes hops of a trace to a MetricSink. Hops are written by Close when they are final,
+// so a hop given up on and answered later by a late reply is written once with its responder,
+// then the trace summary is written and the sink is flushed
+type SinkEncoder struct {
+	sink    MetricSink
+	target  string
+	asns    ASNLookup
+	started time.Time
+	hops    []Hop
+}
+
+// NewSinkEncoder returns the encoder of the trace to target writing to the sink,
+// AS numbers of responders are resolved with options.ASNLookup
+func NewSinkEncoder(sink MetricSink, target string, options Options) *SinkEncoder {
+	return &SinkEncoder{sink: sink, target: target, asns: options.ASNLookup, started: time.Now()}
+}
+
+// Encode adds the hop or the late update of the hop to the trace
+func (e *SinkEncoder) Encode(h Hop) error {
+	e.hops = UpdateHops(e.hops, h)
+	return nil
+}
+
+// Close writes the hops and the summary of the trace, err is reported in the summary
+func (e *SinkEncoder) Close(err error) error {
+	for _, h := range e.hops {
+		if werr := e.sink.WriteHop(e.target, h, hopASN(h, e.asns)); werr != nil {
+			return werr
+		}
+	}
+	if werr := e.sink.WriteSummary(newTraceSummary(e.target, e.hops, e.started, err)); werr != nil {
+		return werr
+	}
+	return e.sink.Flush()
+}
@@ -0,0 +1,113 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSinkTrace() []Hop {
+	start := time.Unix(1700000000, 0)
+	hops := testTrace("10.0.0.1", "*", "192.0.2.9")
+	for i := range hops {
+		hops[i].Sent = start.Add(time.Duration(i) * time.Second)
+		hops[i].Dst.IP = net.ParseIP("192.0.2.9")
+		hops[i].ReplyTTL = 64
+	}
+	hops[0].IXP = &IXP{Name: "DE-CIX", MemberASN: 64500}
+	return hops
+}
+
+func TestInfluxSink(t *testing.T) {
+	var b strings.Builder
+	// the IXP member ASN is used for the responder on the peering LAN, other responders are looked up
+	asns, _ := ParseASNDB(strings.NewReader("10.0.0.0/8 64510\n192.0.2.0/24 64501\n"))
+	e := NewSinkEncoder(NewInfluxSink(&b), "example host", Options{ASNLookup: asns})
+	for _, h := range testSinkTrace() {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(errors.New(`failed "badly"`))
+
+	expected := `traceroute_hop,target=example\ host,hop=1,responder=10.0.0.1,asn=64500 success=true,rtt_ms=1,reply_ttl=64i,late=false 1700000000000000000
+traceroute_hop,target=example\ host,hop=2 success=false,late=false 1700000001000000000
+traceroute_hop,target=example\ host,hop=3,responder=192.0.2.9,asn=64501 success=true,rtt_ms=3,reply_ttl=64i,late=false 1700000002000000000
+traceroute,target=example\ host,dst=192.0.2.9 hops=3i,reached=true,duration_ms=2003,error="failed \"badly\"" 1700000000000000000
+`
+	if b.String() != expected {
+		t.Errorf("unexpected line protocol:\n%v\nexpected:\n%v", b.String(), expected)
+	}
+
+	// the late reply replaces the lost hop, so the lost hop isn't written
+	b.Reset()
+	e = NewSinkEncoder(NewInfluxSink(&b), "example.com", Options{})
+	hops := testSinkTrace()
+	late := hops[1]
+	late.Success, late.Late, late.Node.IP, late.Elapsed = true, true, net.ParseIP("10.0.0.2"), 900*time.Millisecond
+	for _, h := range append(hops, late) {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(nil)
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) != 5 || lines[1] != "traceroute_hop,target=example.com,hop=2,responder=10.0.0.2 success=true,rtt_ms=900,reply_ttl=64i,late=true 1700000001000000000" {
+		t.Errorf("unexpected line protocol of the late reply:\n%v", b.String())
+	}
+}
+
+func TestOTLPSink(t *testing.T) {
+	var b strings.Builder
+	e := NewSinkEncoder(NewOTLPSink(&b), "example.com", Options{})
+	for _, h := range testSinkTrace() {
+		_ = e.Encode(h)
+	}
+	_ = e.Close(nil)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single request, got %v", b.String())
+	}
+	var req struct {
+		ResourceMetrics []struct {
+			ScopeMetrics []struct {
+				Metrics []otlpMetric `json:"metrics"`
+			} `json:"scopeMetrics"`
+		} `json:"resourceMetrics"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]otlpMetric{}
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m
+	}
+	rtt := metrics["traceroute.hop.rtt"].Gauge.DataPoints
+	if len(rtt) != 2 || *rtt[0].AsDouble != 1 || rtt[0].TimeUnixNano != "1700000000000000000" {
+		t.Fatalf("unexpected rtt data points %+v", rtt)
+	}
+	attrs := map[string]string{}
+	for _, a := range rtt[0].Attributes {
+		if a.Value.StringValue != nil {
+			attrs[a.Key] = *a.Value.StringValue
+		} else {
+			attrs[a.Key] = *a.Value.IntValue
+		}
+	}
+	if attrs["target"] != "example.com" || attrs["hop"] != "1" || attrs["responder"] != "10.0.0.1" || attrs["asn"] != "64500" {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	if p := metrics["traceroute.hop.answered"].Gauge.DataPoints; len(p) != 3 || *p[1].AsInt != "0" {
+		t.Errorf("unexpected answered data points %+v", p)
+	}
+	if p := metrics["traceroute.reached"].Gauge.DataPoints; len(p) != 1 || *p[0].AsInt != "1" {
+		t.Errorf("unexpected reached data points %+v", p)
+	}
+
+	// nothing is written without data points
+	b.Reset()
+	_ = NewOTLPSink(&b).Flush()
+	if b.Len() != 0 {
+		t.Errorf("unexpected output %v", b.String())
+	}
+}
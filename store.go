@@ -0,0 +1,337 @@
+package gotraceroute
+
+import (
+	"bufio"
+	"crypto/sha1" // #nosec G505 - used for fingerprints only
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const storeFileExt = ".jsonl"
+
+// TraceRecord is a completed trace kept in the Store
+type TraceRecord struct {
+	// Target is the traced destination as it was passed to Run
+	Target string
+	// Time is the time the trace was started
+	Time time.Time
+	// Fingerprint is the canonical path fingerprint, see PathFingerprint
+	Fingerprint string
+	Hops        []Hop
+}
+
+// PathChange is an event emitted by the Store when the path to a target changes
+type PathChange struct {
+	Target   string
+	Previous TraceRecord
+	Current  TraceRecord
+	// Diff is the difference between the previous and the current path, silent hops of the previous path
+	// are compared by the last responders of these hops, see Store.Add
+	Diff TraceDiff
+}
+
+// StoreOptions type
+type StoreOptions struct {
+	// Retention is the period the traces are kept in the store, 0 means forever
+	Retention time.Duration
+	// OnPathChange is called when the path to a target changes
+	OnPathChange func(change PathChange)
+}
+
+// Store keeps completed traces per target in a directory, one JSON lines file per target.
+// Store is safe for concurrent use, it should be created with OpenStore
+type Store struct {
+	dir     string
+	options StoreOptions
+	mu      sync.Mutex
+	// last is the latest record per target
+	last map[string]TraceRecord
+	// known is the last known path per target, it's used to detect path changes
+	known map[string][]Hop
+	// pruned is the time of the last retention pass per target
+	pruned map[string]time.Time
+}
+
+// OpenStore opens the store in the directory dir, the directory is created if it doesn't exist
+func OpenStore(dir string, options StoreOptions) (s *Store, err error) {
+	if err = os.MkdirAll(dir, 0o750); err != nil {
+		err = fmt.Errorf("can't create store directory: %w", err)
+		return
+	}
+	s = &Store{
+		dir:     dir,
+		options: options,
+		last:    map[string]TraceRecord{},
+		known:   map[string][]Hop{},
+		pruned:  map[string]time.Time{},
+	}
+	return
+}
+
+// PathFingerprint returns a canonical fingerprint of the path: responders are ordered by step,
+// several responders on the same step are sorted and silent hops are skipped,
+// so the same responders give the same fingerprint regardless of the probes order,
+// but a hop that didn't respond at all changes the fingerprint
+func PathFingerprint(hops []Hop) string {
+	steps := diffSteps(hops)
+	var path []string
+	for _, s := range steps {
+		if s.silent() {
+			continue
+		}
+		nodes := make([]string, len(s.nodes))
+		for i, n := range s.nodes {
+			nodes[i] = n.String()
+		}
+		sort.Strings(nodes)
+		path = append(path, fmt.Sprintf("%d:%s", s.step, strings.Join(nodes, ",")))
+	}
+	sum := sha1.Sum([]byte(strings.Join(path, "|"))) // #nosec G401
+	return hex.EncodeToString(sum[:])
+}
+
+// Add stores a completed trace to the target. The trace time is the time the first hop was sent.
+// If the path has changed since the previous trace, the change is returned and OnPathChange is called.
+// A path is considered changed if its fingerprint differs and Diff reports added, removed or changed hops,
+// so lost replies and load-balanced alternates don't produce events.
+// A hop silent in the previous trace is compared by its last responder, so a change hidden
+// behind a silent hop is reported once the hop responds again.
+// OnPathChange is called after the store is unlocked, so it may use the store
+func (s *Store) Add(target string, hops []Hop) (change *PathChange, err error) {
+	if change, err = s.add(target, hops); change != nil && s.options.OnPathChange != nil {
+		s.options.OnPathChange(*change)
+	}
+	return
+}
+
+func (s *Store) add(target string, hops []Hop) (change *PathChange, err error) {
+	rec := TraceRecord{
+		Target:      target,
+		Time:        time.Now(),
+		Fingerprint: PathFingerprint(hops),
+		Hops:        hops,
+	}
+	if len(hops) > 0 && !hops[0].Sent.IsZero() {
+		rec.Time = hops[0].Sent
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, ok := s.last[target]
+	known, loaded := s.known[target]
+	if !loaded {
+		var recs []TraceRecord
+		if recs, err = s.read(target, time.Time{}, time.Time{}); err != nil {
+			return
+		}
+		for _, r := range recs {
+			known = knownPath(known, r.Hops)
+		}
+		if len(recs) > 0 {
+			prev, ok = recs[len(recs)-1], true
+		}
+	}
+
+	if err = s.append(rec); err != nil {
+		return
+	}
+	s.last[target] = rec
+	s.known[target] = knownPath(known, hops)
+
+	if ok && prev.Fingerprint != rec.Fingerprint {
+		if d := Diff(known, rec.Hops); d.Changed() {
+			change = &PathChange{Target: target, Previous: prev, Current: rec, Diff: d}
+		}
+	}
+
+	if s.options.Retention > 0 && rec.Time.Sub(s.pruned[target]) > s.options.Retention/10 {
+		s.pruned[target] = rec.Time
+		err = s.prune(target, rec.Time.Add(-s.options.Retention))
+	}
+	return
+}
+
+// knownPath returns the hops with silent steps replaced by the answered hops of these steps in the known path
+func knownPath(known, hops []Hop) []Hop {
+	answered := map[int]bool{}
+	for i := range hops {
+		if len(hops[i].replies()) > 0 {
+			answered[hops[i].Step] = true
+		}
+	}
+	path := make([]Hop, 0, len(hops))
+	for _, h := range hops {
+		if answered[h.Step] {
+			path = append(path, h)
+			continue
+		}
+		replaced := false
+		for i := range known {
+			if known[i].Step == h.Step && len(known[i].replies()) > 0 {
+				path = append(path, known[i])
+				replaced = true
+			}
+		}
+		if !replaced {
+			path = append(path, h)
+		}
+	}
+	return path
+}
+
+// Query returns traces to the target started in the time range [from, to),
+// zero from or to means the range isn't limited from that side
+func (s *Store) Query(target string, from, to time.Time) ([]TraceRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(target, from, to)
+}
+
+// Last returns the latest trace to the target, ok is false if there are no traces
+func (s *Store) Last(target string) (rec TraceRecord, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok = s.last[target]; ok {
+		return
+	}
+	recs, err := s.read(target, time.Time{}, time.Time{})
+	if len(recs) > 0 {
+		rec, ok = recs[len(recs)-1], true
+		s.last[target] = rec
+	}
+	return
+}
+
+// Targets returns all targets having traces in the store
+func (s *Store) Targets() (targets []string, err error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+storeFileExt))
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		var t string
+		if t, err = url.QueryUnescape(strings.TrimSuffix(filepath.Base(f), storeFileExt)); err != nil {
+			return
+		}
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	return
+}
+
+// Prune removes traces older than the retention period for all targets
+func (s *Store) Prune() (err error) {
+	if s.options.Retention <= 0 {
+		return
+	}
+	targets, err := s.Targets()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, t := range targets {
+		if err = s.prune(t, now.Add(-s.options.Retention)); err != nil {
+			return
+		}
+		s.pruned[t] = now
+	}
+	return
+}
+
+func (s *Store) fileName(target string) string {
+	return filepath.Join(s.dir, url.QueryEscape(target)+storeFileExt)
+}
+
+func (s *Store) append(rec TraceRecord) (err error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(s.fileName(rec.Target), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return
+	}
+	return f.Close()
+}
+
+func (s *Store) read(target string, from, to time.Time) (recs []TraceRecord, err error) {
+	f, err := os.Open(s.fileName(target))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec TraceRecord
+		if err = json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			err = fmt.Errorf("store file %v is corrupted: %w", f.Name(), err)
+			return
+		}
+		if (!from.IsZero() && rec.Time.Before(from)) || (!to.IsZero() && !rec.Time.Before(to)) {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	err = scanner.Err()
+	return
+}
+
+// prune rewrites the target file without traces started before the time
+func (s *Store) prune(target string, before time.Time) (err error) {
+	recs, err := s.read(target, before, time.Time{})
+	if err != nil {
+		return
+	}
+	name := s.fileName(target)
+	if len(recs) == 0 {
+		delete(s.last, target)
+		if err = os.Remove(name); os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range recs {
+		if err = enc.Encode(rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	return os.Rename(tmp, name)
+}
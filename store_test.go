@@ -0,0 +1,105 @@
+package gotraceroute
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func testTraceAt(t time.Time, nodes ...string) []Hop {
+	hops := testTrace(nodes...)
+	for i := range hops {
+		hops[i].Sent = t
+	}
+	return hops
+}
+
+func TestStore(t *testing.T) {
+	var events []PathChange
+	var s *Store
+	s, err := OpenStore(t.TempDir(), StoreOptions{
+		Retention: time.Hour,
+		OnPathChange: func(c PathChange) {
+			// the store is usable from the callback
+			if last, ok, err := s.Last(c.Target); err != nil || !ok || last.Fingerprint != c.Current.Fingerprint {
+				t.Errorf("unexpected last trace in the callback: %v, %v", last, err)
+			}
+			events = append(events, c)
+		},
+	})
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+
+	start := time.Now().Add(-2 * time.Hour)
+	traces := [][]Hop{
+		testTraceAt(start, "10.0.0.1", "192.0.2.1", "8.8.8.8"),
+		testTraceAt(start.Add(90*time.Minute), "10.0.0.1", "*", "8.8.8.8"),
+		testTraceAt(start.Add(100*time.Minute), "10.0.0.1", "192.0.2.1", "8.8.8.8"),
+		testTraceAt(start.Add(110*time.Minute), "10.0.0.1", "192.0.2.7", "8.8.8.8"),
+	}
+	for i, h := range traces {
+		change, err := s.Add("example.com/x", h)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if (change != nil) != (i == 3) {
+			t.Errorf("trace %d: unexpected path change %v", i, change)
+		}
+	}
+	if len(events) != 1 || events[0].Previous.Fingerprint != PathFingerprint(traces[2]) {
+		t.Errorf("expected one path change event, got %v", events)
+	}
+	if PathFingerprint(traces[1]) == PathFingerprint(traces[2]) || PathFingerprint(traces[0]) != PathFingerprint(traces[2]) {
+		t.Errorf("unexpected fingerprints")
+	}
+
+	// the first trace is out of retention period
+	recs, err := s.Query("example.com/x", time.Time{}, time.Time{})
+	if err != nil || len(recs) != 3 {
+		t.Fatalf("expected 3 traces after retention, got %d (%v)", len(recs), err)
+	}
+	recs, err = s.Query("example.com/x", start.Add(95*time.Minute), start.Add(110*time.Minute))
+	if err != nil || len(recs) != 1 || !recs[0].Time.Equal(start.Add(100*time.Minute)) {
+		t.Errorf("unexpected time range query result: %v (%v)", recs, err)
+	}
+
+	targets, err := s.Targets()
+	if err != nil || len(targets) != 1 || targets[0] != "example.com/x" {
+		t.Errorf("unexpected targets: %v (%v)", targets, err)
+	}
+}
+
+func TestStoreSilentHop(t *testing.T) {
+	// the change behind the silent hop is detected, by a reopened store too
+	for _, reopen := range []bool{false, true} {
+		dir := t.TempDir()
+		s, err := OpenStore(dir, StoreOptions{})
+		if err != nil {
+			t.Fatalf("OpenStore failed: %v", err)
+		}
+		start := time.Now()
+		traces := [][]Hop{
+			testTraceAt(start, "10.0.0.1", "192.0.2.1", "8.8.8.8"),
+			testTraceAt(start.Add(time.Minute), "10.0.0.1", "*", "8.8.8.8"),
+			testTraceAt(start.Add(2*time.Minute), "10.0.0.1", "192.0.2.7", "8.8.8.8"),
+		}
+		for i, h := range traces {
+			if i == 2 && reopen {
+				if s, err = OpenStore(dir, StoreOptions{}); err != nil {
+					t.Fatalf("OpenStore failed: %v", err)
+				}
+			}
+			change, err := s.Add("example.com", h)
+			if err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			if i < 2 && change != nil {
+				t.Errorf("trace %d: unexpected path change %v", i, change)
+			}
+			if i == 2 && (change == nil || change.Diff.Hops[1].Kind != HopChanged || !change.Diff.Hops[1].NodesA[0].Equal(net.ParseIP("192.0.2.1"))) {
+				t.Errorf("reopen %v: expected the change of the hop 2, got %+v", reopen, change)
+			}
+		}
+	}
+}
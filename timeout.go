@@ -0,0 +1,54 @@
+package gotraceroute
+
+import "time"
+
+const DefaultMinTimeoutMs = 50
+const DefaultMaxTimeoutMs = 3000
+
+// rtoClockGranularity is the lower bound of the RTT variance term of the timeout
+const rtoClockGranularity = time.Millisecond
+
+// rttEstimator calculates the adaptive probe timeout from RTTs of the answered probes
+// the same way TCP calculates the retransmission timeout (RFC 6298): a smoothed RTT
+// plus four RTT variations, limited by the floor and the ceiling
+type rttEstimator struct {
+	initial  time.Duration
+	floor    time.Duration
+	ceiling  time.Duration
+	srtt     time.Duration
+	rttvar   time.Duration
+	measured bool
+}
+
+func newRTTEstimator(initial, floor, ceiling time.Duration) *rttEstimator {
+	return &rttEstimator{initial: initial, floor: floor, ceiling: ceiling}
+}
+
+// add adds the rtt of an answered probe to the estimate
+func (e *rttEstimator) add(rtt time.Duration) {
+	if !e.measured {
+		e.srtt = rtt
+		e.rttvar = rtt / 2
+		e.measured = true
+		return
+	}
+	delta := e.srtt - rtt
+	if delta < 0 {
+		delta = -delta
+	}
+	e.rttvar = (3*e.rttvar + delta) / 4
+	e.srtt = (7*e.srtt + rtt) / 8
+}
+
+// timeout returns the time to wait for a reply to the probe retried the retry number of times,
+// the timeout is doubled on every retry like TCP backs off retransmissions
+func (e *rttEstimator) timeout(retry int) time.Duration {
+	t := e.initial
+	if e.measured {
+		t = e.srtt + max(rtoClockGranularity, 4*e.rttvar)
+	}
+	for i := 0; i < retry && t < e.ceiling; i++ {
+		t *= 2
+	}
+	return min(max(t, e.floor), e.ceiling)
+}
@@ -0,0 +1,50 @@
+package gotraceroute
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRTTEstimator(t *testing.T) {
+	ms := time.Millisecond
+	e := newRTTEstimator(200*ms, 50*ms, 3000*ms)
+	if to := e.timeout(0); to != 200*ms {
+		t.Errorf("expected the initial timeout before the first sample, got %v", to)
+	}
+	if to := e.timeout(1); to != 400*ms {
+		t.Errorf("expected the doubled timeout on retry, got %v", to)
+	}
+
+	e.add(10 * ms)
+	if to := e.timeout(0); to != 50*ms {
+		t.Errorf("expected the timeout limited by the floor, got %v", to)
+	}
+
+	for i := 0; i < 10; i++ {
+		e.add(600 * ms)
+	}
+	if to := e.timeout(0); to <= 600*ms || to >= 3000*ms {
+		t.Errorf("expected the timeout to follow a long-haul rtt, got %v", to)
+	}
+	if to := e.timeout(5); to != 3000*ms {
+		t.Errorf("expected the timeout limited by the ceiling, got %v", to)
+	}
+
+	e = newRTTEstimator(200*ms, 0, 3000*ms)
+	for i := 0; i < 20; i++ {
+		e.add(100 * ms)
+	}
+	if to := e.timeout(0); to < 100*ms || to > 110*ms {
+		t.Errorf("expected the timeout close to a stable rtt, got %v", to)
+	}
+}
+
+func TestRunAdaptiveTimeout(t *testing.T) {
+	hops, err := RunBlock("127.0.0.1", Options{AdaptiveTimeout: true, MaxHops: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hops) != 1 || !hops[0].Success || hops[0].Late {
+		t.Errorf("unexpected hops %v", hops)
+	}
+}
@@ -0,0 +1,423 @@
+package gotraceroute
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RTTStats is a statistics of round trip time observations
+type RTTStats struct {
+	Count  int
+	Min    time.Duration
+	Max    time.Duration
+	Avg    time.Duration
+	StdDev time.Duration
+	sum    float64
+	sumSq  float64
+}
+
+func (s *RTTStats) add(d time.Duration) {
+	if s.Count == 0 || d < s.Min {
+		s.Min = d
+	}
+	if s.Count == 0 || d > s.Max {
+		s.Max = d
+	}
+	s.Count++
+	s.sum += float64(d)
+	s.sumSq += float64(d) * float64(d)
+	s.update()
+}
+
+// merge adds the observations of o to the statistics
+func (s *RTTStats) merge(o RTTStats) {
+	if o.Count == 0 {
+		return
+	}
+	if s.Count == 0 || o.Min < s.Min {
+		s.Min = o.Min
+	}
+	if s.Count == 0 || o.Max > s.Max {
+		s.Max = o.Max
+	}
+	s.Count += o.Count
+	s.sum += o.sum
+	s.sumSq += o.sumSq
+	s.update()
+}
+
+func (s *RTTStats) update() {
+	avg := s.sum / float64(s.Count)
+	s.Avg = time.Duration(avg)
+	s.StdDev = time.Duration(math.Sqrt(math.Max(s.sumSq/float64(s.Count)-avg*avg, 0)))
+}
+
+// TopologyNode is a router interface seen in traces or an anonymous placeholder of an unresponsive hop
+type TopologyNode struct {
+	// ID is the node identifier, the ip address for responsive nodes
+	ID        string
+	IP        net.IP `json:",omitempty"`
+	Host      string `json:",omitempty"`
+	Anonymous bool
+	// Aliases are the other interface addresses of the same router merged into the node by MergeAliases
+	Aliases []net.IP `json:",omitempty"`
+	// Count is the number of times the node was observed
+	Count int
+	// RTT is the statistics of round trip time to the node
+	RTT RTTStats
+}
+
+// TopologyEdge is a link between nodes seen at consecutive hops
+type TopologyEdge struct {
+	From string
+	To   string
+	// Count is the number of times the edge was observed
+	Count int
+	// RTT is the statistics of RTT difference between the To and the From nodes, e.g. the link latency estimate,
+	// it isn't collected for edges with anonymous nodes
+	RTT RTTStats
+}
+
+type topologyEdgeKey struct {
+	from, to string
+}
+
+// Topology is a directed graph of interfaces merged from any number of traces.
+// It should be created with NewTopology, traces are added with Add
+type Topology struct {
+	nodes map[string]*TopologyNode
+	edges map[topologyEdgeKey]*TopologyEdge
+	// anonymous maps the placeholder position (previous responsive node, next responsive node, distance)
+	// to the placeholder node id, so the same gap in different traces is mapped to the same placeholders
+	anonymous map[string]string
+}
+
+// NewTopology returns an empty topology
+func NewTopology() *Topology {
+	return &Topology{
+		nodes:     map[string]*TopologyNode{},
+		edges:     map[topologyEdgeKey]*TopologyEdge{},
+		anonymous: map[string]string{},
+	}
+}
+
+// topologyStep is the list of responsive hops of one trace step, it's empty for the unresponsive step
+type topologyStep []Hop
+
+// Add merges a trace into the topology. Several responses on the same step (load-balanced paths) are
+// linked with every response of the neighbour steps, unresponsive steps are replaced with anonymous placeholders
+func (t *Topology) Add(hops []Hop) {
+	var steps []topologyStep
+	idx := map[int]int{}
+	for _, h := range hops {
+		i, ok := idx[h.Step]
+		if !ok {
+			i = len(steps)
+			idx[h.Step] = i
+			steps = append(steps, nil)
+		}
+		steps[i] = append(steps[i], h.replies()...)
+	}
+
+	var prev []string
+	var prevHops []Hop
+	lastResponsive := -1
+	for i, s := range steps {
+		var cur []string
+		var curHops []Hop
+		if len(s) == 0 {
+			cur = []string{t.placeholder(steps, lastResponsive, i)}
+			t.node(cur[0], Hop{}).Count++
+		} else {
+			// every reply is counted, a node replied to several probes of the step is linked once
+			for _, h := range s {
+				n := t.node(h.Node.IP.String(), h)
+				n.Count++
+				n.RTT.add(h.Elapsed)
+				known := false
+				for _, id := range cur {
+					known = known || id == n.ID
+				}
+				if !known {
+					cur = append(cur, n.ID)
+					curHops = append(curHops, h)
+				}
+			}
+			lastResponsive = i
+		}
+
+		for pi, from := range prev {
+			for ci, to := range cur {
+				e := t.edge(from, to)
+				e.Count++
+				if len(curHops) > 0 && len(prevHops) > 0 {
+					e.RTT.add(curHops[ci].Elapsed - prevHops[pi].Elapsed)
+				}
+			}
+		}
+		prev, prevHops = cur, curHops
+	}
+}
+
+// placeholder returns the anonymous node id for the unresponsive step i
+func (t *Topology) placeholder(steps []topologyStep, lastResponsive int, i int) string {
+	from, to := "", ""
+	if lastResponsive >= 0 {
+		from = steps[lastResponsive][0].Node.IP.String()
+	}
+	for _, s := range steps[i+1:] {
+		if len(s) > 0 {
+			to = s[0].Node.IP.String()
+			break
+		}
+	}
+	key := fmt.Sprintf("%s|%s|%d", from, to, i-lastResponsive)
+	id, ok := t.anonymous[key]
+	if !ok {
+		id = fmt.Sprintf("*%d", len(t.anonymous)+1)
+		t.anonymous[key] = id
+	}
+	return id
+}
+
+func (t *Topology) node(id string, h Hop) *TopologyNode {
+	n, ok := t.nodes[id]
+	if !ok {
+		n = &TopologyNode{ID: id, IP: h.Node.IP, Host: h.Node.Host, Anonymous: h.Node.IP == nil}
+		t.nodes[id] = n
+	}
+	return n
+}
+
+func (t *Topology) edge(from, to string) *TopologyEdge {
+	k := topologyEdgeKey{from, to}
+	e, ok := t.edges[k]
+	if !ok {
+		e = &TopologyEdge{From: from, To: to}
+		t.edges[k] = e
+	}
+	return e
+}
+
+// MergeAliases turns the interface level graph into the router level one: the interfaces of every router
+// are merged into a single node with the id of the first router interface seen in the topology,
+// edges between interfaces of the same router are dropped
+func (t *Topology) MergeAliases(routers []Router) {
+	rename := map[string]string{}
+	for _, r := range routers {
+		var node *TopologyNode
+		for _, ip := range r.Interfaces {
+			n, ok := t.nodes[ip.String()]
+			if !ok {
+				continue
+			}
+			if node == nil {
+				node = n
+				continue
+			}
+			node.Aliases = append(node.Aliases, n.IP)
+			node.Count += n.Count
+			node.RTT.merge(n.RTT)
+			rename[n.ID] = node.ID
+			delete(t.nodes, n.ID)
+		}
+	}
+	if len(rename) == 0 {
+		return
+	}
+
+	edges := t.edges
+	t.edges = make(map[topologyEdgeKey]*TopologyEdge, len(edges))
+	for k, e := range edges {
+		if to, ok := rename[k.from]; ok {
+			k.from = to
+		}
+		if to, ok := rename[k.to]; ok {
+			k.to = to
+		}
+		if k.from == k.to {
+			continue
+		}
+		m := t.edge(k.from, k.to)
+		m.Count += e.Count
+		m.RTT.merge(e.RTT)
+	}
+}
+
+// Nodes returns the topology nodes sorted by id
+func (t *Topology) Nodes() []TopologyNode {
+	nodes := make([]TopologyNode, 0, len(t.nodes))
+	for _, n := range t.nodes {
+		nodes = append(nodes, *n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// Edges returns the topology edges sorted by the source and destination node ids
+func (t *Topology) Edges() []TopologyEdge {
+	edges := make([]TopologyEdge, 0, len(t.edges))
+	for _, e := range t.edges {
+		edges = append(edges, *e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+func (n *TopologyNode) label() string {
+	if n.Anonymous {
+		return "*"
+	}
+	label := n.ID
+	if n.Host != "" {
+		label = fmt.Sprintf("%s\n%s", n.Host, n.ID)
+	}
+	for _, a := range n.Aliases {
+		label += "\n" + a.String()
+	}
+	return label
+}
+
+func (e *TopologyEdge) label() string {
+	if e.RTT.Count == 0 {
+		return fmt.Sprintf("n=%d", e.Count)
+	}
+	return fmt.Sprintf("n=%d avg=%.2fms", e.Count, float64(e.RTT.Avg.Microseconds())/1000)
+}
+
+// WriteDOT exports the topology in Graphviz DOT format
+func (t *Topology) WriteDOT(w io.Writer) (err error) {
+	p := func(format string, a ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, a...)
+		}
+	}
+	p("digraph traceroute {\n")
+	for _, n := range t.Nodes() {
+		shape := "box"
+		if n.Anonymous {
+			shape = "circle"
+		}
+		p("\t%q [label=%q, shape=%s];\n", n.ID, n.label(), shape)
+	}
+	for _, e := range t.Edges() {
+		p("\t%q -> %q [label=%q];\n", e.From, e.To, e.label())
+	}
+	p("}\n")
+	return
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+func graphMLDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(d.Microseconds())/1000)
+}
+
+// WriteGraphML exports the topology in GraphML format
+func (t *Topology) WriteGraphML(w io.Writer) (err error) {
+	g := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{"host", "node", "host", "string"},
+			{"anonymous", "node", "anonymous", "boolean"},
+			{"aliases", "node", "aliases", "string"},
+			{"ncount", "node", "count", "int"},
+			{"nrtt", "node", "rtt_avg_ms", "double"},
+			{"count", "edge", "count", "int"},
+			{"rttmin", "edge", "rtt_min_ms", "double"},
+			{"rttavg", "edge", "rtt_avg_ms", "double"},
+			{"rttmax", "edge", "rtt_max_ms", "double"},
+		},
+		Graph: graphMLGraph{ID: "traceroute", EdgeDefault: "directed"},
+	}
+	for _, n := range t.Nodes() {
+		aliases := make([]string, len(n.Aliases))
+		for i, a := range n.Aliases {
+			aliases[i] = a.String()
+		}
+		g.Graph.Nodes = append(g.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{"host", n.Host},
+			{"anonymous", fmt.Sprint(n.Anonymous)},
+			{"aliases", strings.Join(aliases, ",")},
+			{"ncount", fmt.Sprint(n.Count)},
+			{"nrtt", graphMLDuration(n.RTT.Avg)},
+		}})
+	}
+	for _, e := range t.Edges() {
+		data := []graphMLData{{"count", fmt.Sprint(e.Count)}}
+		if e.RTT.Count > 0 {
+			data = append(data, graphMLData{"rttmin", graphMLDuration(e.RTT.Min)},
+				graphMLData{"rttavg", graphMLDuration(e.RTT.Avg)},
+				graphMLData{"rttmax", graphMLDuration(e.RTT.Max)})
+		}
+		g.Graph.Edges = append(g.Graph.Edges, graphMLEdge{Source: e.From, Target: e.To, Data: data})
+	}
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err = enc.Encode(g); err != nil {
+		return
+	}
+	_, err = io.WriteString(w, "\n")
+	return
+}
+
+// WriteJSON exports the topology in JSON format as an object with Nodes and Edges arrays
+func (t *Topology) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(struct {
+		Nodes []TopologyNode
+		Edges []TopologyEdge
+	}{t.Nodes(), t.Edges()})
+}
@@ -0,0 +1,62 @@
+package gotraceroute
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestTopology(t *testing.T) {
+	topo := NewTopology()
+	topo.Add(testTrace("10.0.0.1", "*", "192.0.2.1", "8.8.8.8"))
+	topo.Add(testTrace("10.0.0.1", "*", "192.0.2.1", "192.0.2.2,192.0.2.3"))
+	topo.Add(testTrace("10.0.0.1", "192.0.2.9", "*"))
+
+	nodes := topo.Nodes()
+	if len(nodes) != 8 {
+		t.Errorf("expected 8 nodes, got %d: %v", len(nodes), nodes)
+	}
+	var edges []string
+	for _, e := range topo.Edges() {
+		edges = append(edges, e.From+">"+e.To)
+		if e.From == "192.0.2.1" && e.To == "8.8.8.8" && (e.Count != 1 || e.RTT.Avg.Milliseconds() != 1) {
+			t.Errorf("unexpected edge annotation: %+v", e)
+		}
+		if e.From == "10.0.0.1" && e.To == "*1" && e.Count != 2 {
+			t.Errorf("expected the same placeholder for the same gap, got %+v", e)
+		}
+	}
+	expected := "*1>192.0.2.1 10.0.0.1>*1 10.0.0.1>192.0.2.9 192.0.2.1>192.0.2.2 192.0.2.1>192.0.2.3 192.0.2.1>8.8.8.8 192.0.2.9>*2"
+	if strings.Join(edges, " ") != expected {
+		t.Errorf("unexpected edges: %v", edges)
+	}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"dot":     func(b *bytes.Buffer) error { return topo.WriteDOT(b) },
+		"graphml": func(b *bytes.Buffer) error { return topo.WriteGraphML(b) },
+		"json":    func(b *bytes.Buffer) error { return topo.WriteJSON(b) },
+	} {
+		var b bytes.Buffer
+		if err := write(&b); err != nil || !strings.Contains(b.String(), "192.0.2.9") {
+			t.Errorf("%v export failed: %v\n%v", name, err, b.String())
+		}
+	}
+
+	// responders of all probes of a hop are linked, a responder of several probes is linked once
+	probes := NewTopology()
+	probes.Add(testProbeTrace("10.0.0.1,10.0.0.1,10.0.0.1", "192.0.2.4,*,192.0.2.5"))
+	edges = nil
+	for _, e := range probes.Edges() {
+		edges = append(edges, fmt.Sprintf("%v>%v:%v", e.From, e.To, e.Count))
+	}
+	if strings.Join(edges, " ") != "10.0.0.1>192.0.2.4:1 10.0.0.1>192.0.2.5:1" {
+		t.Errorf("unexpected edges of the trace with several probes per hop: %v", edges)
+	}
+
+	topo.MergeAliases([]Router{{Interfaces: []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.9")}}})
+	if len(topo.Nodes()) != 7 || len(topo.Edges()) != 7 {
+		t.Errorf("unexpected router level topology: %v %v", topo.Nodes(), topo.Edges())
+	}
+}
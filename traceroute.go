@@ -33,6 +33,7 @@ func destIP(dest string) (destAddr net.IP, err error) {
 // to the remote host.
 // Run is unblocked and returns a communication channel where the caller should read the Hop data
 // On finish or error the communication channel will be closed
+// If a reply to a hop given up on arrives later, the hop is sent again marked as Late, see UpdateHops
 // Outbound packets are UDP packets and inbound packets are ICMP.
 func Run(ctx context.Context, dest string, options Options) (c chan Hop, err error) {
 	destAddr, err := destIP(dest)
@@ -45,6 +46,7 @@ func Run(ctx context.Context, dest string, options Options) (c chan Hop, err err
 		return
 	}
 
+	flow.capture = options.Capture
 	c = make(chan Hop)
 	go func() {
 		_, _ = run(ctx, options, flow, c)
@@ -61,6 +63,10 @@ func Run(ctx context.Context, dest string, options Options) (c chan Hop, err err
 // the elapsed time and its IP address.
 // Outbound packets are UDP packets and inbound packets are ICMP.
 func RunBlock(dest string, options Options) (hops []Hop, err error) {
+	return runBlock(context.Background(), dest, options)
+}
+
+func runBlock(ctx context.Context, dest string, options Options) (hops []Hop, err error) {
 	destAddr, err := destIP(dest)
 	if err != nil {
 		return
@@ -71,43 +77,104 @@ func RunBlock(dest string, options Options) (hops []Hop, err error) {
 		return
 	}
 
-	hops, err = run(context.Background(), options, flow, nil)
+	flow.capture = options.Capture
+	hops, err = run(ctx, options, flow, nil)
 
 	flow.close()
 
 	return
 }
 
+// probe is a sent probe packet waiting for a reply
+type probe struct {
+	id  int
+	ttl int
+	// n is the number of the probe of the ttl when several probes are sent to every hop,
+	// otherwise it's the number of the attempt
+	n    int
+	sent time.Time
+}
+
+// probeTable is the table of outstanding probes indexed by the packet id,
+// it lets replies to probes of previous TTLs be matched whenever they arrive
+type probeTable map[int]probe
+
+// add adds the probe to the table and removes probes older than window, their replies are considered lost
+func (t probeTable) add(p probe, window time.Duration) {
+	for id, o := range t {
+		if p.sent.Sub(o.sent) > window {
+			delete(t, id)
+		}
+	}
+	t[p.id] = p
+}
+
+// answered removes all probes of the ttl
+func (t probeTable) answered(ttl int) {
+	for id, o := range t {
+		if o.ttl == ttl {
+			delete(t, id)
+		}
+	}
+}
+
 //nolint:funlen
 //nolint:gocognit
+//nolint:gocyclo
 func run(ctx context.Context, options Options, f flow, c chan<- Hop) (hops []Hop, err error) {
 	var hop Hop
 	port := options.port()
-
-	var dstAddrBytes [4]byte
-	copy(dstAddrBytes[:], f.destAddr.To4())
+	collector := options.collector()
 
 	ttl := options.startTTL()
+	// every probe of the hop is sent once if several probes are sent to every hop, otherwise the probe is retried
+	multiProbe := options.probesPerHop() > 1
 
 	var packetIdx uint16
 	payload := bytes.Repeat([]byte{0x00}, options.payloadSize())
 	retry := 0
+	rtt := newRTTEstimator(options.timeout(), options.minTimeout(), options.maxTimeout())
+	// probes waiting for replies, a reply to the given up probe is still accepted while waiting for the next ones
+	probes := probeTable{}
+	lateWindow := max(options.timeout(), options.maxTimeout())
+	// results of probes sent to the current ttl and the first reply if several probes are sent to every hop
+	var results []HopProbe
+	var firstReply *Hop
+	var ttlStart time.Time
 
-	var recvBuff = make([]byte, 100)
+	var recvBuff = make([]byte, recvBufferSize)
 
 	for ttl <= options.maxHops() && !hop.Node.IP.Equal(f.destAddr) {
+		// the probe is sent and its wait is started once the rate limit allows
+		if err = options.RateLimiter.Wait(ctx, f.destAddr); err != nil {
+			break
+		}
 		start := time.Now()
+		if len(results) == 0 {
+			ttlStart = start
+		}
 		packetIdx = (packetIdx + 1) % (1<<6 - 1)
 		packetID := int(f.flowID<<6 + packetIdx)
-		pkt := newUDPPacket(f.destAddr, port, port, ttl, packetID, payload)
+		var pkt []byte
+		if pkt, err = newUDPPacket(f.destAddr, port, port, ttl, packetID, payload); err != nil {
+			break
+		}
 		// Send a UDP packet
-		e := syscall.Sendto(f.sSocket, pkt, 0, &syscall.SockaddrInet4{Port: port, Addr: dstAddrBytes})
+		e := f.send(pkt, f.destAddr, port)
 		if e != nil {
 			err = fmt.Errorf("sendto error: %w", e)
 			break
 		}
+		collector.ProbeSent(f.destAddr, ttl)
+		n := len(results)
+		if !multiProbe {
+			n = retry
+		}
+		probes.add(probe{id: packetID, ttl: ttl, n: n, sent: start}, lateWindow)
 
-		timeout := options.timeout()
+		var answer *Hop
+		timeout := options.probeTimeout(rtt, retry)
+		deadline := start.Add(timeout)
 		// in general the raw socket can receive any ICMP packets from anyone,
 		// so we need to filter and drop anyone else's ICMP packets and continue to receive
 		// with reduced timeout till the overall timeout happened or our target packet received
@@ -115,16 +182,13 @@ func run(ctx context.Context, options Options, f flow, c chan<- Hop) (hops []Hop
 		// It makes no sense if we use BPF filter, but we leave this solution here for a general case,
 		// if bpf filter disabled or not supported by OS, this solution guarantees a correct reception at least for single-threaded traceroute
 		for timeout > 0 {
-			// solution with using SetsockoptTimeval at every Recvfrom isn't optimal,
-			// it's better to use poll (epoll)
-			tv := syscall.NsecToTimeval(timeout.Nanoseconds())
 			// This sets the timeout to wait for a response from the remote host
-			if err = syscall.SetsockoptTimeval(f.rSocket, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+			if err = f.setRecvTimeout(timeout); err != nil {
 				return
 			}
-			_, _, e := syscall.Recvfrom(f.rSocket, recvBuff, 0)
+			n, e := f.recv(recvBuff)
 			now := time.Now()
-			elapsed := now.Sub(start)
+			timeout = deadline.Sub(now)
 
 			if e != nil {
 				// timeout
@@ -133,22 +197,54 @@ func run(ctx context.Context, options Options, f flow, c chan<- Hop) (hops []Hop
 				} else {
 					// something bad (lack of resources or something else)
 					time.Sleep(time.Millisecond * 10)
-					timeout -= time.Since(start)
+					timeout = time.Until(deadline)
 				}
 				continue
 			}
 
-			hop, e = extractMessage(recvBuff, !options.DontResolve)
-			if e != nil || hop.ID != packetID {
-				timeout -= elapsed
+			reply, e := extractMessage(recvBuff[:n], !options.DontResolve)
+			p, ok := probes[reply.ID]
+			if e != nil || !ok {
+				collector.ForeignPacket(f.destAddr)
 				continue
 			}
+			rtt.add(now.Sub(p.sent))
+			collector.ReplyReceived(f.destAddr, p.ttl, now.Sub(p.sent))
 
-			hop.Success = true
-			hop.Step = ttl
-			hop.Sent = start
-			hop.Received = now
-			hop.Elapsed = elapsed
+			reply.Success = true
+			reply.Step = p.ttl
+			reply.Sent = p.sent
+			reply.Received = now
+			reply.Elapsed = now.Sub(p.sent)
+			reply.Late = p.id != packetID
+			if !multiProbe {
+				reply.Attempt = p.n
+			}
+			reply.IXP = options.IXPDB.Lookup(reply.Node.IP)
+			if multiProbe {
+				delete(probes, p.id)
+			} else {
+				probes.answered(p.ttl)
+			}
+
+			switch {
+			case p.ttl != ttl:
+				// the late reply to a previous TTL given up on, the hop is delivered again as an update
+				if i := p.ttl - options.startTTL(); i >= 0 && i < len(hops) && hops[i].updateLate(reply, p.n) {
+					if c != nil {
+						c <- hops[i]
+					}
+				}
+				continue
+			case multiProbe && p.id != packetID:
+				// the late reply to a previous probe of the current ttl
+				results[p.n] = reply.probe()
+				if firstReply == nil {
+					firstReply = &reply
+				}
+				continue
+			}
+			answer = &reply
 			break
 		}
 
@@ -163,7 +259,32 @@ func run(ctx context.Context, options Options, f flow, c chan<- Hop) (hops []Hop
 			break
 		}
 
-		if timeout <= 0 {
+		if answer == nil {
+			collector.Timeout(f.destAddr, ttl)
+		}
+
+		if multiProbe {
+			r := HopProbe{Elapsed: time.Since(start)}
+			if answer != nil {
+				r = answer.probe()
+				if firstReply == nil {
+					firstReply = answer
+				}
+			}
+			results = append(results, r)
+			if len(results) < options.probesPerHop() {
+				continue
+			}
+			if firstReply != nil {
+				hop = *firstReply
+			} else {
+				hop = newHop(int(f.flowID), f.socketAddr, f.destAddr, ttl)
+				hop.Sent = ttlStart
+				hop.Elapsed = time.Since(ttlStart)
+			}
+			hop.Probes = results
+			results, firstReply = nil, nil
+		} else if answer == nil {
 			retry++
 			if retry <= options.retries() {
 				continue
@@ -171,6 +292,8 @@ func run(ctx context.Context, options Options, f flow, c chan<- Hop) (hops []Hop
 			hop = newHop(int(f.flowID), f.socketAddr, f.destAddr, ttl)
 			hop.Sent = start
 			hop.Elapsed = time.Since(start)
+		} else {
+			hop = *answer
 		}
 
 		hops = append(hops, hop)
@@ -135,6 +135,30 @@ func TestRun4Concurrent(t *testing.T) {
 	}
 }
 
+func TestRun5Many(t *testing.T) {
+	targets := []string{"127.0.0.1", "127.0.0.2", testErrHosts[0], "127.0.0.3", "localhost"}
+	started := time.Now()
+	var results []BatchResult
+	for r := range RunMany(context.Background(), targets, BatchOptions{Concurrency: 2, ProbesPerSecond: 20, Ordered: true}) {
+		results = append(results, r)
+	}
+	if len(results) != len(targets) {
+		t.Fatalf("TestRun5Many failed. Expected %d results, got %d", len(targets), len(results))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Target != targets[i] {
+			t.Errorf("TestRun5Many failed. Result %d is out of order: %v", i, r.Target)
+		}
+		if (r.Err != nil) != (r.Target == testErrHosts[0]) || (r.Err == nil && len(r.Hops) == 0) {
+			t.Errorf("TestRun5Many failed. Unexpected result to %v: %v", r.Target, r.Err)
+		}
+	}
+	// 4 probes with the rate 20/sec and the burst 1 take at least 150ms
+	if time.Since(started) < 150*time.Millisecond {
+		t.Errorf("TestRun5Many failed. Probe rate limit isn't applied")
+	}
+}
+
 func testRun(ctx context.Context, host string, options Options) (hops []Hop, err error) {
 	c, err := Run(ctx, host, options)
 
@@ -147,3 +171,26 @@ func testRun(ctx context.Context, host string, options Options) (hops []Hop, err
 	}
 	return
 }
+
+func TestProbeTable(t *testing.T) {
+	now := time.Now()
+	probes := probeTable{}
+	probes.add(probe{id: 1, ttl: 1, sent: now}, time.Second)
+	probes.add(probe{id: 2, ttl: 1, sent: now.Add(time.Millisecond)}, time.Second)
+	probes.add(probe{id: 3, ttl: 2, sent: now.Add(time.Millisecond * 2)}, time.Second)
+	probes.answered(1)
+	if _, ok := probes[3]; len(probes) != 1 || !ok {
+		t.Errorf("probes of the answered ttl should be removed: %v", probes)
+	}
+	probes.add(probe{id: 4, ttl: 3, sent: now.Add(time.Second * 2)}, time.Second)
+	if _, ok := probes[4]; len(probes) != 1 || !ok {
+		t.Errorf("probes older than the window should be removed: %v", probes)
+	}
+
+	hops := UpdateHops(nil, Hop{Step: 1})
+	hops = UpdateHops(hops, Hop{Step: 2})
+	hops = UpdateHops(hops, Hop{Step: 1, Success: true, Late: true})
+	if len(hops) != 2 || !hops[0].Late || hops[1].Step != 2 {
+		t.Errorf("late hop should replace the hop of the same step: %v", hops)
+	}
+}
@@ -0,0 +1,645 @@
+package gotraceroute
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/net/ipv4"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// warts file format constants, see the warts(5) manual page of scamper
+const (
+	wartsMagic          = 0x1205
+	wartsTypeList       = 0x0001
+	wartsTypeCycleStart = 0x0002
+	wartsTypeCycleStop  = 0x0004
+	wartsTypeTrace      = 0x0006
+	wartsAddrIPv4       = 0x01
+	wartsAddrIPv6       = 0x02
+	// wartsListID and wartsCycleID are the file ids of the only list and cycle the traces belong to
+	wartsListID  = 1
+	wartsCycleID = 1
+	// wartsTraceTypeUDPParis is the scamper trace type of UDP probes with the constant destination port
+	wartsTraceTypeUDPParis = 0x05
+	// wartsTraceFlagAllAttempts means all attempts are sent to every hop
+	wartsTraceFlagAllAttempts = 0x01
+	// wartsHopFlagReplyTTL means the reply TTL of the hop record is valid
+	wartsHopFlagReplyTTL = 0x10
+	// wartsCycleHostname is the hostname parameter of the cycle start object
+	wartsCycleHostname = 2
+)
+
+// trace object parameters
+const (
+	wartsTraceListID     = 1
+	wartsTraceCycleID    = 2
+	wartsTraceSrcID      = 3
+	wartsTraceDstID      = 4
+	wartsTraceStart      = 5
+	wartsTraceStopReason = 6
+	wartsTraceStopData   = 7
+	wartsTraceFlags      = 8
+	wartsTraceAttempts   = 9
+	wartsTraceHopLimit   = 10
+	wartsTraceType       = 11
+	wartsTraceProbeSize  = 12
+	wartsTraceSrcPort    = 13
+	wartsTraceDstPort    = 14
+	wartsTraceFirstTTL   = 15
+	wartsTraceTOS        = 16
+	wartsTraceWait       = 17
+	wartsTraceLoops      = 18
+	wartsTraceHopCount   = 19
+	wartsTraceGapLimit   = 20
+	wartsTraceGapAction  = 21
+	wartsTraceLoopAction = 22
+	wartsTraceProbeCount = 23
+	wartsTraceInterval   = 24
+	wartsTraceConfidence = 25
+	wartsTraceSrc        = 26
+	wartsTraceDst        = 27
+)
+
+// hop record parameters
+const (
+	wartsHopAddrID     = 1
+	wartsHopProbeTTL   = 2
+	wartsHopReplyTTL   = 3
+	wartsHopFlags      = 4
+	wartsHopProbeID    = 5
+	wartsHopRTT        = 6
+	wartsHopICMP       = 7
+	wartsHopProbeSize  = 8
+	wartsHopReplySize  = 9
+	wartsHopReplyIPID  = 10
+	wartsHopTOS        = 11
+	wartsHopNextHopMTU = 12
+	wartsHopQuotedLen  = 13
+	wartsHopQuotedTTL  = 14
+	wartsHopTCPFlags   = 15
+	wartsHopQuotedTOS  = 16
+	wartsHopICMPExt    = 17
+	wartsHopAddr       = 18
+	wartsHopTx         = 19
+)
+
+// WartsStopReason is the reason the trace has stopped as scamper records it
+type WartsStopReason int
+
+const (
+	WartsStopNone WartsStopReason = iota
+	WartsStopCompleted
+	WartsStopUnreach
+	WartsStopICMP
+	WartsStopLoop
+	WartsStopGapLimit
+	WartsStopError
+	WartsStopHopLimit
+	WartsStopGSS
+	WartsStopHalted
+)
+
+// wartsParams builds the flags and parameters of a warts object or a hop record,
+// parameters have to be added in order of their flags
+type wartsParams struct {
+	flags []byte
+	data  []byte
+}
+
+func (p *wartsParams) set(flag int) {
+	i := (flag - 1) / 7
+	for len(p.flags) <= i {
+		p.flags = append(p.flags, 0)
+	}
+	p.flags[i] |= 1 << ((flag - 1) % 7)
+}
+
+func (p *wartsParams) uint8(flag int, v int) {
+	p.set(flag)
+	p.data = append(p.data, uint8(v))
+}
+
+func (p *wartsParams) uint16(flag int, v int) {
+	p.set(flag)
+	p.data = binary.BigEndian.AppendUint16(p.data, uint16(v))
+}
+
+func (p *wartsParams) uint32(flag int, v uint32) {
+	p.set(flag)
+	p.data = binary.BigEndian.AppendUint32(p.data, v)
+}
+
+func (p *wartsParams) timeval(flag int, t time.Time) {
+	p.set(flag)
+	p.data = binary.BigEndian.AppendUint32(p.data, uint32(t.Unix()))
+	p.data = binary.BigEndian.AppendUint32(p.data, uint32(t.Nanosecond()/1000))
+}
+
+// addr adds the address written in full, addresses aren't referenced by ids
+func (p *wartsParams) addr(flag int, ip net.IP) {
+	p.set(flag)
+	if ip4 := ip.To4(); ip4 != nil {
+		p.data = append(append(p.data, 4, wartsAddrIPv4), ip4...)
+	} else {
+		p.data = append(append(p.data, 16, wartsAddrIPv6), ip.To16()...)
+	}
+}
+
+func (p *wartsParams) string(flag int, s string) {
+	p.set(flag)
+	p.data = append(append(p.data, s...), 0)
+}
+
+// appendTo appends the flags with the continuation bits, the parameters length and the parameters
+func (p *wartsParams) appendTo(b []byte) []byte {
+	if len(p.flags) == 0 {
+		return append(b, 0)
+	}
+	for i, f := range p.flags {
+		if i < len(p.flags)-1 {
+			f |= 0x80
+		}
+		b = append(b, f)
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(len(p.data)))
+	return append(b, p.data...)
+}
+
+// WartsWriter writes traces to a file in the scamper warts format, so they can be processed with
+// sc_analysis_dump, sc_warts2json and other tools of scamper and CAIDA pipelines.
+// The traces belong to a single list and cycle, the cycle is stopped by Close.
+// WartsWriter is safe for concurrent use
+type WartsWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWartsWriter writes the list and the cycle start objects to w and returns the writer of traces
+func NewWartsWriter(w io.Writer) (*WartsWriter, error) {
+	list := binary.BigEndian.AppendUint32(nil, wartsListID)
+	list = binary.BigEndian.AppendUint32(list, wartsListID)
+	list = append(list, "gotraceroute"...)
+	list = (&wartsParams{}).appendTo(append(list, 0))
+
+	cycle := binary.BigEndian.AppendUint32(nil, wartsCycleID)
+	cycle = binary.BigEndian.AppendUint32(cycle, wartsListID)
+	cycle = binary.BigEndian.AppendUint32(cycle, wartsCycleID)
+	cycle = binary.BigEndian.AppendUint32(cycle, uint32(time.Now().Unix()))
+	var params wartsParams
+	if hostname, err := os.Hostname(); err == nil {
+		params.string(wartsCycleHostname, hostname)
+	}
+	cycle = params.appendTo(cycle)
+
+	ww := &WartsWriter{w: w}
+	if err := ww.writeObject(wartsTypeList, list); err != nil {
+		return nil, err
+	}
+	if err := ww.writeObject(wartsTypeCycleStart, cycle); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+func (ww *WartsWriter) writeObject(objectType uint16, data []byte) error {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+	b := binary.BigEndian.AppendUint16(make([]byte, 0, 8+len(data)), wartsMagic)
+	b = binary.BigEndian.AppendUint16(b, objectType)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	_, err := ww.w.Write(append(b, data...))
+	return err
+}
+
+// WriteTrace writes the trace hops executed with options as a warts trace object, err is the traceroute error
+// the stop reason is derived from. Every answered probe is written as a hop record with the probe and reply TTLs,
+// the RTT and the ICMP type and code, lost probes and late updates aren't recorded like scamper does
+func (ww *WartsWriter) WriteTrace(hops []Hop, options Options, err error) error {
+	probeSize := ipv4.HeaderLen + 8 + options.payloadSize()
+	multiProbe := options.probesPerHop() > 1
+
+	var p wartsParams
+	p.uint32(wartsTraceListID, wartsListID)
+	p.uint32(wartsTraceCycleID, wartsCycleID)
+	if len(hops) > 0 {
+		p.timeval(wartsTraceStart, hops[0].Sent)
+	}
+	stop := WartsStopNone
+	switch {
+	case Reached(hops):
+		stop = WartsStopCompleted
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		stop = WartsStopHalted
+	case err != nil:
+		stop = WartsStopError
+	case len(hops) > 0:
+		stop = WartsStopHopLimit
+	}
+	p.uint8(wartsTraceStopReason, int(stop))
+	if multiProbe {
+		p.uint8(wartsTraceFlags, wartsTraceFlagAllAttempts)
+		p.uint8(wartsTraceAttempts, min(options.probesPerHop(), 255))
+	} else {
+		p.uint8(wartsTraceAttempts, min(options.retries()+1, 255))
+	}
+	p.uint8(wartsTraceHopLimit, options.maxHops())
+	p.uint8(wartsTraceType, wartsTraceTypeUDPParis)
+	p.uint16(wartsTraceProbeSize, probeSize)
+	p.uint16(wartsTraceSrcPort, options.port())
+	p.uint16(wartsTraceDstPort, options.port())
+	p.uint8(wartsTraceFirstTTL, options.startTTL())
+	wait := (options.timeout() + time.Second - 1) / time.Second
+	if options.AdaptiveTimeout {
+		wait = (options.maxTimeout() + time.Second - 1) / time.Second
+	}
+	p.uint8(wartsTraceWait, min(int(wait), 255))
+	if len(hops) > 0 {
+		p.uint16(wartsTraceHopCount, hops[len(hops)-1].Step)
+		if hops[0].Src.IP != nil {
+			p.addr(wartsTraceSrc, hops[0].Src.IP)
+		}
+		p.addr(wartsTraceDst, hops[0].Dst.IP)
+	}
+
+	var records [][]byte
+	for _, h := range hops {
+		probes := h.Probes
+		// a probe is retried if several probes aren't sent to every hop, the answered attempt is numbered then
+		attempt := 0
+		if len(probes) == 0 {
+			probes = []HopProbe{h.probe()}
+			attempt = h.Attempt
+		}
+		// the hop fields are the fields of its first answered probe
+		first := true
+		for n, r := range probes {
+			if !r.Success {
+				continue
+			}
+			var hp wartsParams
+			hp.uint8(wartsHopProbeTTL, h.Step)
+			if r.ReplyTTL != 0 {
+				hp.uint8(wartsHopReplyTTL, r.ReplyTTL)
+				hp.uint8(wartsHopFlags, wartsHopFlagReplyTTL)
+			}
+			// scamper numbers attempts from 1
+			hp.uint8(wartsHopProbeID, attempt+n+1)
+			hp.uint32(wartsHopRTT, uint32(r.Elapsed.Microseconds()))
+			hp.uint16(wartsHopICMP, r.IcmpType<<8|r.IcmpCode)
+			hp.uint16(wartsHopProbeSize, probeSize)
+			if r.ReplySize != 0 {
+				hp.uint16(wartsHopReplySize, r.ReplySize)
+			}
+			if first {
+				hp.uint16(wartsHopReplyIPID, h.ReplyIPID)
+			}
+			hp.addr(wartsHopAddr, r.Node.IP)
+			if first && !h.Sent.IsZero() {
+				hp.timeval(wartsHopTx, h.Sent)
+			}
+			records = append(records, hp.appendTo(nil))
+			first = false
+		}
+	}
+
+	b := p.appendTo(nil)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(records)))
+	for _, r := range records {
+		b = append(b, r...)
+	}
+	// the end of optional trace data
+	b = binary.BigEndian.AppendUint16(b, 0)
+	return ww.writeObject(wartsTypeTrace, b)
+}
+
+// Close writes the cycle stop object, the underlying writer isn't closed
+func (ww *WartsWriter) Close() error {
+	b := binary.BigEndian.AppendUint32(nil, wartsCycleID)
+	b = binary.BigEndian.AppendUint32(b, uint32(time.Now().Unix()))
+	return ww.writeObject(wartsTypeCycleStop, append(b, 0))
+}
+
+// WartsEncoder writes the trace as a warts file with a single trace, the file is written by Close.
+// Late updates replace hops of the same step
+type WartsEncoder struct {
+	w       io.Writer
+	options Options
+	hops    []Hop
+}
+
+// NewWartsEncoder returns the encoder of the trace executed with options
+func NewWartsEncoder(w io.Writer, options Options) *WartsEncoder {
+	return &WartsEncoder{w: w, options: options}
+}
+
+// Encode adds the hop to the trace
+func (e *WartsEncoder) Encode(h Hop) error {
+	e.hops = UpdateHops(e.hops, h)
+	return nil
+}
+
+// Close writes the warts file, the stop reason of the trace is derived from err
+func (e *WartsEncoder) Close(err error) error {
+	ww, werr := NewWartsWriter(e.w)
+	if werr != nil {
+		return werr
+	}
+	if werr = ww.WriteTrace(e.hops, e.options, err); werr != nil {
+		return werr
+	}
+	return ww.Close()
+}
+
+// WartsTrace is a trace read from a warts file
+type WartsTrace struct {
+	Src        net.IP
+	Dst        net.IP
+	Start      time.Time
+	StopReason WartsStopReason
+	// Type is the scamper trace type, e.g. 0x05 for UDP paris traceroute
+	Type      int
+	Attempts  int
+	HopLimit  int
+	FirstTTL  int
+	ProbeSize int
+	SrcPort   int
+	DstPort   int
+	Wait      time.Duration
+	// Hops are rebuilt from hop records, a hop is added for every TTL from FirstTTL to the last probed one,
+	// every attempt is in Hop.Probes if all attempts were sent to every hop
+	Hops []Hop
+}
+
+// WartsReader reads traces from a warts file, other objects are skipped
+type WartsReader struct {
+	r io.Reader
+}
+
+// NewWartsReader returns the reader of traces of the warts file
+func NewWartsReader(r io.Reader) *WartsReader {
+	return &WartsReader{r: r}
+}
+
+// wartsDecoder reads values of a warts object, the first error stops reading
+type wartsDecoder struct {
+	data  []byte
+	off   int
+	addrs []net.IP
+	err   error
+}
+
+func (d *wartsDecoder) next(n int) []byte {
+	if d.err != nil || d.off+n > len(d.data) {
+		if d.err == nil {
+			d.err = errors.New("truncated warts object")
+		}
+		return make([]byte, n)
+	}
+	b := d.data[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *wartsDecoder) uint8() int {
+	return int(d.next(1)[0])
+}
+
+func (d *wartsDecoder) uint16() int {
+	return int(binary.BigEndian.Uint16(d.next(2)))
+}
+
+func (d *wartsDecoder) uint32() uint32 {
+	return binary.BigEndian.Uint32(d.next(4))
+}
+
+func (d *wartsDecoder) timeval() time.Time {
+	sec, usec := d.uint32(), d.uint32()
+	return time.Unix(int64(sec), int64(usec)*1000)
+}
+
+// addr reads the address written in full or referenced by its id in the object address table
+func (d *wartsDecoder) addr() net.IP {
+	l := d.uint8()
+	if l == 0 {
+		id := int(d.uint32())
+		if id >= len(d.addrs) {
+			if d.err == nil {
+				d.err = fmt.Errorf("unknown warts address id %d", id)
+			}
+			return nil
+		}
+		return d.addrs[id]
+	}
+	_ = d.uint8()
+	ip := net.IP(append([]byte(nil), d.next(l)...))
+	d.addrs = append(d.addrs, ip)
+	return ip
+}
+
+// params reads the flags and the parameters length, it returns the function telling if the flag is set
+// and the offset of the parameters end, unknown parameters are skipped to it
+func (d *wartsDecoder) params() (isSet func(flag int) bool, end int) {
+	var flags []byte
+	for {
+		f := d.uint8()
+		flags = append(flags, byte(f))
+		if f&0x80 == 0 || d.err != nil {
+			break
+		}
+	}
+	isSet = func(flag int) bool {
+		i := (flag - 1) / 7
+		return i < len(flags) && flags[i]&(1<<((flag-1)%7)) != 0
+	}
+	if len(flags) == 1 && flags[0] == 0 {
+		return isSet, d.off
+	}
+	l := d.uint16()
+	return isSet, d.off + l
+}
+
+// skipTo moves to the end of parameters
+func (d *wartsDecoder) skipTo(end int) {
+	if d.err == nil && end > len(d.data) {
+		d.err = errors.New("truncated warts object")
+	}
+	if d.err == nil {
+		d.off = end
+	}
+}
+
+// ReadTrace returns the next trace of the file, io.EOF is returned at the end of the file
+func (wr *WartsReader) ReadTrace() (t WartsTrace, err error) {
+	header := make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(wr.r, header); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = errors.New("truncated warts object header")
+			}
+			return
+		}
+		if binary.BigEndian.Uint16(header) != wartsMagic {
+			err = errors.New("not a warts file")
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[4:8]))
+		if _, err = io.ReadFull(wr.r, data); err != nil {
+			err = fmt.Errorf("truncated warts object: %w", err)
+			return
+		}
+		if binary.BigEndian.Uint16(header[2:4]) == wartsTypeTrace {
+			return decodeWartsTrace(data)
+		}
+	}
+}
+
+// wartsTraceSizes are sizes of the fixed size trace parameters
+var wartsTraceSizes = map[int]int{
+	wartsTraceListID: 4, wartsTraceCycleID: 4, wartsTraceSrcID: 4, wartsTraceDstID: 4, wartsTraceStart: 8,
+	wartsTraceStopReason: 1, wartsTraceStopData: 1, wartsTraceFlags: 1, wartsTraceAttempts: 1, wartsTraceHopLimit: 1,
+	wartsTraceType: 1, wartsTraceProbeSize: 2, wartsTraceSrcPort: 2, wartsTraceDstPort: 2, wartsTraceFirstTTL: 1,
+	wartsTraceTOS: 1, wartsTraceWait: 1, wartsTraceLoops: 1, wartsTraceHopCount: 2, wartsTraceGapLimit: 1,
+	wartsTraceGapAction: 1, wartsTraceLoopAction: 1, wartsTraceProbeCount: 2, wartsTraceInterval: 1, wartsTraceConfidence: 1,
+}
+
+// wartsHopSizes are sizes of the fixed size hop record parameters
+var wartsHopSizes = map[int]int{
+	wartsHopAddrID: 4, wartsHopProbeTTL: 1, wartsHopReplyTTL: 1, wartsHopFlags: 1, wartsHopProbeID: 1, wartsHopRTT: 4,
+	wartsHopICMP: 2, wartsHopProbeSize: 2, wartsHopReplySize: 2, wartsHopReplyIPID: 2, wartsHopTOS: 1,
+	wartsHopNextHopMTU: 2, wartsHopQuotedLen: 2, wartsHopQuotedTTL: 1, wartsHopTCPFlags: 1, wartsHopQuotedTOS: 1,
+}
+
+// wartsRecord is a hop record of a warts trace
+type wartsRecord struct {
+	probeTTL int
+	probeID  int
+	sent     time.Time
+	hop      HopProbe
+	ipid     int
+}
+
+//nolint:gocyclo
+func decodeWartsTrace(data []byte) (t WartsTrace, err error) {
+	d := &wartsDecoder{data: data}
+	isSet, end := d.params()
+	hopCount, traceFlags := 0, 0
+	for flag := wartsTraceListID; flag <= wartsTraceDst && d.err == nil; flag++ {
+		if !isSet(flag) {
+			continue
+		}
+		switch flag {
+		case wartsTraceStart:
+			t.Start = d.timeval()
+		case wartsTraceStopReason:
+			t.StopReason = WartsStopReason(d.uint8())
+		case wartsTraceFlags:
+			traceFlags = d.uint8()
+		case wartsTraceAttempts:
+			t.Attempts = d.uint8()
+		case wartsTraceHopLimit:
+			t.HopLimit = d.uint8()
+		case wartsTraceType:
+			t.Type = d.uint8()
+		case wartsTraceProbeSize:
+			t.ProbeSize = d.uint16()
+		case wartsTraceSrcPort:
+			t.SrcPort = d.uint16()
+		case wartsTraceDstPort:
+			t.DstPort = d.uint16()
+		case wartsTraceFirstTTL:
+			t.FirstTTL = d.uint8()
+		case wartsTraceWait:
+			t.Wait = time.Duration(d.uint8()) * time.Second
+		case wartsTraceHopCount:
+			hopCount = d.uint16()
+		case wartsTraceSrc:
+			t.Src = d.addr()
+		case wartsTraceDst:
+			t.Dst = d.addr()
+		default:
+			d.next(wartsTraceSizes[flag])
+		}
+	}
+	d.skipTo(end)
+
+	records := map[int][]wartsRecord{}
+	n := d.uint16()
+	for i := 0; i < n && d.err == nil; i++ {
+		isSet, end := d.params()
+		r := wartsRecord{hop: HopProbe{Success: true}}
+		for flag := wartsHopAddrID; flag <= wartsHopTx && d.err == nil; flag++ {
+			if !isSet(flag) {
+				continue
+			}
+			switch flag {
+			case wartsHopProbeTTL:
+				r.probeTTL = d.uint8()
+			case wartsHopReplyTTL:
+				r.hop.ReplyTTL = d.uint8()
+			case wartsHopProbeID:
+				// attempts are numbered from 1, probeID is the index of the attempt
+				r.probeID = max(d.uint8()-1, 0)
+			case wartsHopRTT:
+				r.hop.Elapsed = time.Duration(d.uint32()) * time.Microsecond
+			case wartsHopICMP:
+				icmp := d.uint16()
+				r.hop.IcmpType, r.hop.IcmpCode = icmp>>8, icmp&0xff
+			case wartsHopReplySize:
+				r.hop.ReplySize = d.uint16()
+			case wartsHopReplyIPID:
+				r.ipid = d.uint16()
+			case wartsHopICMPExt:
+				d.next(d.uint16())
+			case wartsHopAddr:
+				ip := d.addr()
+				r.hop.Node = Addr{IP: ip, Class: ClassifyAddr(ip)}
+			case wartsHopTx:
+				r.sent = d.timeval()
+			default:
+				d.next(wartsHopSizes[flag])
+			}
+		}
+		d.skipTo(end)
+		records[r.probeTTL] = append(records[r.probeTTL], r)
+	}
+	if d.err != nil {
+		err = d.err
+		return
+	}
+
+	allAttempts := t.Attempts > 1 && traceFlags&wartsTraceFlagAllAttempts != 0
+	for ttl := max(t.FirstTTL, 1); ttl <= hopCount; ttl++ {
+		h := Hop{Src: Addr{IP: t.Src}, Dst: Addr{IP: t.Dst}, Step: ttl, DstPort: t.DstPort}
+		if allAttempts {
+			h.Probes = make([]HopProbe, t.Attempts)
+		}
+		var first *wartsRecord
+		for i, r := range records[ttl] {
+			if allAttempts && r.probeID < t.Attempts && !h.Probes[r.probeID].Success {
+				h.Probes[r.probeID] = r.hop
+			}
+			if first == nil || r.probeID < first.probeID {
+				first = &records[ttl][i]
+			}
+		}
+		if first != nil {
+			h.Success, h.Node, h.Elapsed = true, first.hop.Node, first.hop.Elapsed
+			h.IcmpType, h.IcmpCode, h.ReplyTTL, h.ReplySize = first.hop.IcmpType, first.hop.IcmpCode, first.hop.ReplyTTL, first.hop.ReplySize
+			h.ReplyIPID, h.Sent = first.ipid, first.sent
+			if !allAttempts {
+				h.Attempt = first.probeID
+			}
+			if !h.Sent.IsZero() {
+				h.Received = h.Sent.Add(h.Elapsed)
+			}
+		}
+		t.Hops = append(t.Hops, h)
+	}
+	return
+}
@@ -0,0 +1,150 @@
+package gotraceroute
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWarts(t *testing.T) {
+	start := time.Unix(1700000000, 123456000)
+	dst := net.ParseIP("192.0.2.9")
+	hop := func(step int, node string, rtt time.Duration) Hop {
+		h := Hop{Step: step, Src: Addr{IP: net.ParseIP("10.0.0.100")}, Dst: Addr{IP: dst}, DstPort: DefaultPort,
+			Sent: start.Add(time.Duration(step) * time.Second)}
+		if node != "" {
+			h.Success, h.Node.IP, h.Elapsed = true, net.ParseIP(node), rtt
+			h.Node.Class = ClassifyAddr(h.Node.IP)
+			h.Received = h.Sent.Add(rtt)
+			h.IcmpType, h.ReplyTTL, h.ReplySize, h.ReplyIPID = 11, 64-step, 36, 1000+step
+		}
+		return h
+	}
+	single := []Hop{hop(1, "10.0.0.1", 1500*time.Microsecond), hop(2, "", 0), hop(3, "192.0.2.9", 12*time.Millisecond)}
+	single[2].IcmpType, single[2].IcmpCode = 3, 3
+	// the destination answered the retry
+	single[2].Attempt = 1
+
+	multi := []Hop{hop(1, "10.0.0.1", time.Millisecond), hop(2, "198.51.100.1", 5*time.Millisecond)}
+	multi[0].Probes = []HopProbe{multi[0].probe(), {Success: true, Node: multi[0].Node, Elapsed: 2 * time.Millisecond, IcmpType: 11}, {}}
+	multi[1].Probes = []HopProbe{{}, multi[1].probe(), {}}
+
+	var b bytes.Buffer
+	ww, err := NewWartsWriter(&b)
+	if err != nil {
+		t.Fatalf("NewWartsWriter failed: %v", err)
+	}
+	if err = ww.WriteTrace(single, Options{PayloadSize: 32}, nil); err != nil {
+		t.Fatalf("WriteTrace failed: %v", err)
+	}
+	if err = ww.WriteTrace(multi, Options{ProbesPerHop: 3, MaxHops: 2}, context.Canceled); err != nil {
+		t.Fatalf("WriteTrace failed: %v", err)
+	}
+	_ = ww.Close()
+
+	r := NewWartsReader(&b)
+	tr, err := r.ReadTrace()
+	if err != nil {
+		t.Fatalf("ReadTrace failed: %v", err)
+	}
+	if tr.StopReason != WartsStopCompleted || !tr.Start.Equal(single[0].Sent) || !tr.Dst.Equal(dst) || tr.ProbeSize != 60 ||
+		tr.DstPort != DefaultPort || tr.FirstTTL != 1 || tr.Attempts != DefaultRetries+1 || tr.Type != wartsTraceTypeUDPParis {
+		t.Errorf("unexpected trace parameters: %+v", tr)
+	}
+	if len(tr.Hops) != len(single) {
+		t.Fatalf("expected %v hops, got %v", len(single), len(tr.Hops))
+	}
+	for i, h := range tr.Hops {
+		s := single[i]
+		if h.Success != s.Success || !h.Node.IP.Equal(s.Node.IP) || h.Node.Class != s.Node.Class || h.Elapsed != s.Elapsed ||
+			h.IcmpType != s.IcmpType || h.IcmpCode != s.IcmpCode || h.ReplyTTL != s.ReplyTTL || h.ReplySize != s.ReplySize ||
+			h.ReplyIPID != s.ReplyIPID || h.Step != s.Step || !h.Dst.IP.Equal(s.Dst.IP) || !h.Src.IP.Equal(s.Src.IP) ||
+			h.Attempt != s.Attempt {
+			t.Errorf("hop %v has changed:\n%+v\n%+v", s.Step, s, h)
+		}
+		if s.Success && !h.Sent.Equal(s.Sent) {
+			t.Errorf("hop %v sent time has changed: %v", s.Step, h.Sent)
+		}
+	}
+
+	if tr, err = r.ReadTrace(); err != nil {
+		t.Fatalf("ReadTrace failed: %v", err)
+	}
+	if tr.StopReason != WartsStopHalted || tr.Attempts != 3 || tr.HopLimit != 2 || len(tr.Hops) != 2 {
+		t.Fatalf("unexpected trace: %+v", tr)
+	}
+	for i, h := range tr.Hops {
+		for n, p := range h.Probes {
+			if e := multi[i].Probes[n]; p.Success != e.Success || p.Elapsed != e.Elapsed || !p.Node.IP.Equal(e.Node.IP) {
+				t.Errorf("probe %v of hop %v has changed: %+v", n, h.Step, p)
+			}
+		}
+		if !h.Success || !h.Node.IP.Equal(multi[i].Node.IP) || h.Elapsed != multi[i].Elapsed {
+			t.Errorf("hop %v has changed: %+v", h.Step, h)
+		}
+	}
+
+	if _, err = r.ReadTrace(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got %v", err)
+	}
+	if _, err = NewWartsReader(bytes.NewReader([]byte("not a warts file"))).ReadTrace(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+// wartsScamperTrace is a trace object encoded the way scamper writes it: attempts are numbered from 1
+// and an address written once is referenced by its id in the object address table
+const wartsScamperTrace = "" +
+	"1205 0006 0000008c" + // object header
+	"b3 ff 95 30 002b" + // trace flags and parameters length
+	"00000001 00000001 6553f100 0001e240" + // list id, cycle id, start
+	"01 01 03 1e 05 003c 829a 829a 01 05 0002" + // stop reason, flags, attempts, hop limit, type, sizes, ports, first ttl, wait, hop count
+	"04 01 0a000064 04 01 c0000209" + // src and dst addresses, ids 0 and 1
+	"0003" + // hop records
+	"fe 87 18 001e 01 3f 10 01 000005dc 0b00 003c 0038 03e9 04 01 0a000001 6553f101 0001e240" + // ttl 1 attempt 1
+	"fe 87 08 0015 01 3f 10 03 000009c4 0b00 003c 0038 03eb 00 00000002" + // ttl 1 attempt 3 from the address id 2
+	"fe 87 08 0015 02 3a 10 02 00002ee0 0303 003c 0038 03ea 00 00000001" + // ttl 2 attempt 2 from the dst address
+	"0000"
+
+func TestWartsScamper(t *testing.T) {
+	data, err := hex.DecodeString(strings.ReplaceAll(wartsScamperTrace, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewWartsReader(bytes.NewReader(data)).ReadTrace()
+	if err != nil {
+		t.Fatalf("ReadTrace failed: %v", err)
+	}
+	if tr.Attempts != 3 || len(tr.Hops) != 2 || !tr.Src.Equal(net.ParseIP("10.0.0.100")) || !tr.Start.Equal(time.Unix(1700000000, 123456000)) {
+		t.Fatalf("unexpected trace: %+v", tr)
+	}
+	expected := [][]time.Duration{{1500 * time.Microsecond, 0, 2500 * time.Microsecond}, {0, 12 * time.Millisecond, 0}}
+	for i, h := range tr.Hops {
+		for n, p := range h.Probes {
+			if p.Success != (expected[i][n] != 0) || p.Elapsed != expected[i][n] {
+				t.Errorf("unexpected attempt %v of hop %v: %+v", n+1, h.Step, p)
+			}
+		}
+	}
+	if h := tr.Hops[0]; !h.Node.IP.Equal(net.ParseIP("10.0.0.1")) || h.Elapsed != 1500*time.Microsecond || h.ReplyIPID != 1001 {
+		t.Errorf("unexpected hop 1: %+v", h)
+	}
+	if h := tr.Hops[1]; !h.Node.IP.Equal(tr.Dst) || h.IcmpType != 3 || h.IcmpCode != 3 || h.ReplyTTL != 0x3a {
+		t.Errorf("unexpected hop 2: %+v", h)
+	}
+
+	// attempts are written numbered from 1 too
+	var b bytes.Buffer
+	ww, _ := NewWartsWriter(&b)
+	_ = ww.WriteTrace(tr.Hops, Options{ProbesPerHop: 3}, nil)
+	written, err := NewWartsReader(&b).ReadTrace()
+	if err != nil || len(written.Hops) != 2 || !written.Hops[0].Probes[2].Success || written.Hops[0].Probes[1].Success {
+		t.Errorf("unexpected written trace: %+v (%v)", written, err)
+	}
+}